@@ -0,0 +1,418 @@
+package goutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ArchiveFormat is an archive format that can create and extract archives,
+// such as tar, tar.gz and zip.
+type ArchiveFormat interface {
+	// Name returns the unique name of the format, such as "tar.gz".
+	Name() string
+	// Extensions returns the file extensions of the format, such as ".tar.gz" and ".tgz".
+	Extensions() []string
+	// Match reports whether the magic bytes at the beginning of an archive belong to the format.
+	// NOTE:
+	//
+	//	magic is at most ArchiveMagicSize bytes, and may be shorter for small archives.
+	Match(magic []byte) bool
+	// Archive archives the src file or directory to dstWriter,
+	// with the same includePrefix, logOutput and ignoreElem semantics as TarGzTo.
+	Archive(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) error
+	// Extract extracts the archive read from src into the dstDir directory,
+	// with the same safety rules as UnTarGzFrom.
+	Extract(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error
+}
+
+// MatcherArchiver is implemented by the archive formats which can skip the files matched by an IgnoreMatcher,
+// including all the built-in formats.
+type MatcherArchiver interface {
+	// ArchiveWithMatcher is like Archive,
+	// but also skips the files matched by ignore, whose paths are relative to src.
+	ArchiveWithMatcher(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) error
+}
+
+// ArchiveMagicSize is the number of leading bytes used to detect the archive format.
+const ArchiveMagicSize = 512
+
+// ErrUnknownArchiveFormat is returned when the archive format cannot be determined.
+var ErrUnknownArchiveFormat = errors.New("archive: unknown format")
+
+// Built-in archive formats.
+var (
+	// ArchiveTar is the plain tar format.
+	ArchiveTar ArchiveFormat = tarFormat{}
+	// ArchiveTarGz is the gzip compressed tar format.
+	ArchiveTarGz ArchiveFormat = tarGzFormat{}
+	// ArchiveZip is the zip format.
+	ArchiveZip ArchiveFormat = zipFormat{}
+)
+
+var archiveFormats = struct {
+	list []ArchiveFormat
+	rwmu sync.RWMutex
+}{
+	list: []ArchiveFormat{ArchiveTarGz, ArchiveZip, ArchiveTar},
+}
+
+// RegisterArchiveFormat registers the archive format, such as tar.zst.
+// If a format with the same name is already registered, replaces it.
+// NOTE:
+//
+//	When detecting the format, the formats are matched in the order of registration.
+func RegisterArchiveFormat(format ArchiveFormat) {
+	archiveFormats.rwmu.Lock()
+	defer archiveFormats.rwmu.Unlock()
+	for i, f := range archiveFormats.list {
+		if f.Name() == format.Name() {
+			archiveFormats.list[i] = format
+			return
+		}
+	}
+	archiveFormats.list = append(archiveFormats.list, format)
+}
+
+// ArchiveFormats returns all the registered archive formats.
+func ArchiveFormats() []ArchiveFormat {
+	archiveFormats.rwmu.RLock()
+	defer archiveFormats.rwmu.RUnlock()
+	return append([]ArchiveFormat(nil), archiveFormats.list...)
+}
+
+// LookupArchiveFormat returns the registered archive format by name.
+func LookupArchiveFormat(name string) (ArchiveFormat, bool) {
+	for _, f := range ArchiveFormats() {
+		if f.Name() == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// ArchiveFormatByExt returns the registered archive format by the extension of filename.
+// The longest matched extension wins, e.g. ".tar.gz" is preferred to ".gz".
+func ArchiveFormatByExt(filename string) (ArchiveFormat, bool) {
+	filename = strings.ToLower(filename)
+	var format ArchiveFormat
+	var extLen int
+	for _, f := range ArchiveFormats() {
+		for _, ext := range f.Extensions() {
+			if len(ext) > extLen && strings.HasSuffix(filename, strings.ToLower(ext)) {
+				format, extLen = f, len(ext)
+			}
+		}
+	}
+	return format, format != nil
+}
+
+// DetectArchiveFormat detects the archive format from the magic bytes of src.
+// It returns a reader that still yields the whole archive, including the magic bytes.
+func DetectArchiveFormat(src io.Reader) (ArchiveFormat, io.Reader, error) {
+	magic := make([]byte, ArchiveMagicSize)
+	n, err := io.ReadFull(src, magic)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+	magic = magic[:n]
+	r := io.MultiReader(bytes.NewReader(magic), src)
+	format := matchArchiveFormat(magic)
+	if format == nil {
+		return nil, r, ErrUnknownArchiveFormat
+	}
+	return format, r, nil
+}
+
+func matchArchiveFormat(magic []byte) ArchiveFormat {
+	for _, f := range ArchiveFormats() {
+		if f.Match(magic) {
+			return f
+		}
+	}
+	return nil
+}
+
+// Archive archives the src file or directory to the dst file,
+// the format is determined by the extension of dst.
+func Archive(src, dst string, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) (err error) {
+	return ArchiveWithMatcher(src, dst, includePrefix, logOutput, nil, ignoreElem...)
+}
+
+// ArchiveWithMatcher is like Archive,
+// but also skips the files matched by ignore, whose paths are relative to src.
+// NOTE:
+//
+//	If ignore is not nil, the format must implement MatcherArchiver.
+func ArchiveWithMatcher(src, dst string, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) (err error) {
+	format, ok := ArchiveFormatByExt(dst)
+	if !ok {
+		return ErrUnknownArchiveFormat
+	}
+	archive := format.Archive
+	if ignore != nil {
+		m, ok := format.(MatcherArchiver)
+		if !ok {
+			return fmt.Errorf("archive: format %s does not support IgnoreMatcher", format.Name())
+		}
+		archive = func(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) error {
+			return m.ArchiveWithMatcher(src, dstWriter, includePrefix, logOutput, ignore, ignoreElem...)
+		}
+	}
+	fw, err := os.Create(dst)
+	if err != nil {
+		return
+	}
+	err = archive(src, fw, includePrefix, logOutput, ignoreElem...)
+	fw.Close()
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// Extract extracts the src archive file into the dstDir directory.
+// The format is detected from the magic bytes, or else from the extension of src.
+func Extract(src, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	fr, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+	magic := make([]byte, ArchiveMagicSize)
+	n, err := io.ReadFull(fr, magic)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	format := matchArchiveFormat(magic[:n])
+	if format == nil {
+		var ok bool
+		if format, ok = ArchiveFormatByExt(src); !ok {
+			return ErrUnknownArchiveFormat
+		}
+	}
+	// Rewind so that formats which need random access, such as zip, can use the file directly.
+	if _, err = fr.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return format.Extract(fr, dstDir, logOutput, limit...)
+}
+
+// ExtractFrom extracts the archive read from src into the dstDir directory.
+// The format is detected from the magic bytes.
+func ExtractFrom(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	format, r, err := DetectArchiveFormat(src)
+	if err != nil {
+		return err
+	}
+	return format.Extract(r, dstDir, logOutput, limit...)
+}
+
+type tarFormat struct{}
+
+func (tarFormat) Name() string { return "tar" }
+
+func (tarFormat) Extensions() []string { return []string{".tar"} }
+
+func (tarFormat) Match(magic []byte) bool {
+	// The POSIX and GNU tar headers have "ustar" at offset 257.
+	return len(magic) >= 262 && string(magic[257:262]) == "ustar"
+}
+
+func (f tarFormat) Archive(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) error {
+	return f.ArchiveWithMatcher(src, dstWriter, includePrefix, logOutput, nil, ignoreElem...)
+}
+
+func (tarFormat) ArchiveWithMatcher(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) error {
+	w, err := newArchiveWalker(src, includePrefix, ignoreElem)
+	if err != nil {
+		return err
+	}
+	w.ignore = ignore
+	return writeTar(w, dstWriter, "tar", logOutput)
+}
+
+func (tarFormat) Extract(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	return untar(src, dstDir, "tar", logOutput, limit...)
+}
+
+type tarGzFormat struct{}
+
+func (tarGzFormat) Name() string { return "tar.gz" }
+
+func (tarGzFormat) Extensions() []string { return []string{".tar.gz", ".tgz"} }
+
+func (tarGzFormat) Match(magic []byte) bool {
+	return len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+func (tarGzFormat) Archive(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) error {
+	return TarGzTo(src, dstWriter, includePrefix, logOutput, ignoreElem...)
+}
+
+func (tarGzFormat) ArchiveWithMatcher(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) error {
+	return TarGzToWithMatcher(src, dstWriter, includePrefix, logOutput, ignore, ignoreElem...)
+}
+
+func (tarGzFormat) Extract(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	return UnTarGzFrom(src, dstDir, logOutput, limit...)
+}
+
+type zipFormat struct{}
+
+func (zipFormat) Name() string { return "zip" }
+
+func (zipFormat) Extensions() []string { return []string{".zip"} }
+
+func (zipFormat) Match(magic []byte) bool {
+	// Local file header, or end of central directory of an empty archive.
+	return bytes.HasPrefix(magic, []byte("PK\x03\x04")) || bytes.HasPrefix(magic, []byte("PK\x05\x06"))
+}
+
+func (f zipFormat) Archive(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) error {
+	return f.ArchiveWithMatcher(src, dstWriter, includePrefix, logOutput, nil, ignoreElem...)
+}
+
+func (zipFormat) ArchiveWithMatcher(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) error {
+	w, err := newArchiveWalker(src, includePrefix, ignoreElem)
+	if err != nil {
+		return err
+	}
+	w.ignore = ignore
+	zw := zip.NewWriter(dstWriter)
+	defer zw.Close()
+	return w.walk(func(name, fileName string, fi os.FileInfo) error {
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		hdr.Method = zip.Deflate
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		n, err := copyFileTo(fw, fileName)
+		if err != nil {
+			return err
+		}
+		if logOutput != nil {
+			logOutput("zip: packaged %s, written %d bytes\n", hdr.Name, n)
+		}
+		return nil
+	})
+}
+
+func (zipFormat) Extract(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	x, err := newExtractor(dstDir, limit...)
+	if err != nil {
+		return err
+	}
+	ra, size, cleanup, err := readerAtOf(src, x.limit.MaxBytes)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if err = x.countEntry(); err != nil {
+			return err
+		}
+		if err = extractZipFile(x, f, logOutput); err != nil {
+			return err
+		}
+	}
+	return x.finish()
+}
+
+func extractZipFile(x *extractor, f *zip.File, logOutput func(string, ...interface{})) error {
+	mode := f.Mode()
+	switch {
+	case mode.IsDir():
+		return x.mkdir(f.Name, mode, f.Modified)
+	case mode&os.ModeSymlink != 0:
+		fr, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer fr.Close()
+		// The content of a symlink entry is the link target.
+		linkname, err := ioutil.ReadAll(io.LimitReader(fr, 4096))
+		if err != nil {
+			return err
+		}
+		return x.symlink(f.Name, string(linkname))
+	case mode.IsRegular():
+		fr, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer fr.Close()
+		n, err := x.writeFile(f.Name, fr, mode, f.Modified)
+		if err == nil && logOutput != nil {
+			logOutput("zip: extracted %s, written %d bytes\n", f.Name, n)
+		}
+		return err
+	default:
+		return nil
+	}
+}
+
+// readerAtOf returns the random access reader of src and its size, for reading zip.
+// If src is not seekable, it is spooled to a temporary file, which is removed by cleanup.
+// NOTE:
+//
+//	If maxBytes > 0 and the non-seekable src is larger than it, ErrExtractTooLarge is returned,
+//	so that a hostile stream can not exhaust the disk.
+func readerAtOf(src io.Reader, maxBytes int64) (ra io.ReaderAt, size int64, cleanup func(), err error) {
+	cleanup = func() {}
+	if ra, ok := src.(io.ReaderAt); ok {
+		if s, ok := src.(io.Seeker); ok {
+			cur, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, 0, cleanup, err
+			}
+			size, err := s.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, 0, cleanup, err
+			}
+			if _, err = s.Seek(cur, io.SeekStart); err != nil {
+				return nil, 0, cleanup, err
+			}
+			if cur == 0 {
+				return ra, size, cleanup, nil
+			}
+			return io.NewSectionReader(ra, cur, size-cur), size - cur, cleanup, nil
+		}
+	}
+	f, err := ioutil.TempFile("", "goutil-zip-*")
+	if err != nil {
+		return nil, 0, cleanup, err
+	}
+	cleanup = func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if maxBytes > 0 {
+		src = io.LimitReader(src, maxBytes+1)
+	}
+	size, err = io.Copy(f, src)
+	if err == nil && maxBytes > 0 && size > maxBytes {
+		err = ErrExtractTooLarge
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, func() {}, err
+	}
+	return f, size, cleanup, nil
+}
@@ -0,0 +1,86 @@
+package goutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFormats(t *testing.T) {
+	src := t.TempDir()
+	if err := WriteFile(filepath.Join(src, "a", "b.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []ArchiveFormat{ArchiveTar, ArchiveTarGz, ArchiveZip} {
+		var buf = bytes.NewBuffer(nil)
+		if err := format.Archive(src, buf, false, t.Logf, ".git"); err != nil {
+			t.Fatalf("%s: %v", format.Name(), err)
+		}
+		detected, r, err := DetectArchiveFormat(bytes.NewReader(buf.Bytes()))
+		if err != nil || detected.Name() != format.Name() {
+			t.Fatalf("%s: detected %v, %v", format.Name(), detected, err)
+		}
+		dst := t.TempDir()
+		if err = detected.Extract(r, dst, t.Logf); err != nil {
+			t.Fatalf("%s: %v", format.Name(), err)
+		}
+		b, err := os.ReadFile(filepath.Join(dst, "a", "b.txt"))
+		if err != nil || string(b) != "hello" {
+			t.Fatalf("%s: got %q, %v", format.Name(), b, err)
+		}
+		if FileExists(filepath.Join(dst, ".git")) {
+			t.Fatalf("%s: .git should be ignored", format.Name())
+		}
+	}
+}
+
+func TestArchiveFile(t *testing.T) {
+	src := t.TempDir()
+	if err := WriteFile(filepath.Join(src, "b.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".tar", ".tgz", ".tar.gz", ".zip"} {
+		dst := filepath.Join(t.TempDir(), "x"+ext)
+		if err := Archive(src, dst, true, nil); err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		dstDir := t.TempDir()
+		if err := Extract(dst, dstDir, nil, ExtractLimit{MaxEntries: 1}); err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if !FileExists(filepath.Join(dstDir, filepath.Base(src), "b.txt")) {
+			t.Fatalf("%s: b.txt is not extracted", ext)
+		}
+	}
+	if err := Archive(src, filepath.Join(t.TempDir(), "x.rar"), false, nil); err != ErrUnknownArchiveFormat {
+		t.Fatalf("expect ErrUnknownArchiveFormat, got %v", err)
+	}
+}
+
+func TestExtractZipStreamLimit(t *testing.T) {
+	src := t.TempDir()
+	if err := WriteFile(filepath.Join(src, "big.txt"), bytes.Repeat([]byte("0123456789"), 1024), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var buf = bytes.NewBuffer(nil)
+	if err := ArchiveZip.Archive(src, buf, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	// The non-seekable stream larger than MaxBytes is rejected before it is read entirely.
+	stream := io.MultiReader(bytes.NewReader(buf.Bytes()), bytes.NewReader(make([]byte, 1<<20)))
+	if err := ExtractFrom(stream, t.TempDir(), nil, ExtractLimit{MaxBytes: 512}); err != ErrExtractTooLarge {
+		t.Fatalf("expect ErrExtractTooLarge, got %v", err)
+	}
+	dst := t.TempDir()
+	if err := ExtractFrom(struct{ io.Reader }{bytes.NewReader(buf.Bytes())}, dst, nil, ExtractLimit{MaxBytes: 1 << 20}); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(filepath.Join(dst, "big.txt")) {
+		t.Fatal("big.txt is not extracted")
+	}
+}
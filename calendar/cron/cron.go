@@ -1,9 +1,11 @@
 package cron
 
 import (
+	"context"
 	"log"
 	"runtime"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/andeya/goutil/calendar"
@@ -13,13 +15,30 @@ import (
 // specified by the schedule. It may be started, stopped, and the entries may
 // be inspected while running.
 type Cron struct {
-	entries  []*Entry
-	stop     chan struct{}
-	add      chan *Entry
-	snapshot chan []*Entry
-	running  bool
-	ErrorLog *log.Logger
-	location *time.Location
+	entries   []*Entry
+	stop      chan struct{}
+	add       chan *Entry
+	remove    chan EntryID
+	pause     chan pauseRequest
+	snapshot  chan []*Entry
+	running   bool
+	runningMu sync.Mutex // protects running, nextID and the settings before Start
+	nextID    EntryID
+	wrappers  []JobWrapper
+	store     Store
+	locker    Locker
+	hooks     Hooks
+	ErrorLog  *log.Logger
+	location  *time.Location
+}
+
+// EntryID identifies an entry within a Cron instance.
+// The zero value is not a valid ID.
+type EntryID int
+
+type pauseRequest struct {
+	id     EntryID
+	paused bool
 }
 
 // Job is an interface for submitted cron jobs.
@@ -39,6 +58,10 @@ type Schedule interface {
 
 // Entry consists of a schedule and the func to execute on that schedule.
 type Entry struct {
+	// ID is the cron-assigned ID of this entry, which may be used to look up a
+	// snapshot or remove it.
+	ID EntryID
+
 	// The schedule on which this job should be run.
 	Schedule Schedule
 
@@ -52,6 +75,54 @@ type Entry struct {
 
 	// The Job to run.
 	Job Job
+
+	// Paused is true if the job is paused, then it is not run until resumed.
+	Paused bool
+
+	// Name identifies the entry in the Store, across the restarts of the process.
+	// The entry without name is not persisted.
+	Name string
+
+	// MisfirePolicy decides how to handle the runs missed while the process is down.
+	MisfirePolicy MisfirePolicy
+
+	// wrappers are the JobWrappers of the entry.
+	wrappers []JobWrapper
+
+	// historySize is the number of the recent executions recorded.
+	historySize int
+
+	// history records the recent executions, shared by the snapshots.
+	history *history
+
+	// wrappedJob is the Job decorated with the JobWrappers, which is actually run.
+	wrappedJob Job
+}
+
+// Valid returns true if this is not the zero entry.
+func (e Entry) Valid() bool { return e.ID != 0 }
+
+// EntryOption configures an entry when it is added.
+// A JobWrapper is also an EntryOption, which decorates the job of the entry.
+type EntryOption interface {
+	applyEntry(*Entry)
+}
+
+type entryOptionFunc func(*Entry)
+
+func (f entryOptionFunc) applyEntry(e *Entry) { f(e) }
+
+func (w JobWrapper) applyEntry(e *Entry) { e.wrappers = append(e.wrappers, w) }
+
+// WithName sets the name of the entry, which identifies it in the Store.
+func WithName(name string) EntryOption {
+	return entryOptionFunc(func(e *Entry) { e.Name = name })
+}
+
+// WithMisfirePolicy sets the policy for the runs missed while the process is down.
+// It takes effect only if the entry has a name and the Cron has a Store.
+func WithMisfirePolicy(policy MisfirePolicy) EntryOption {
+	return entryOptionFunc(func(e *Entry) { e.MisfirePolicy = policy })
 }
 
 // byTime is a wrapper for sorting the entry array by time
@@ -83,6 +154,8 @@ func NewWithLocation(location *time.Location) *Cron {
 	return &Cron{
 		entries:  nil,
 		add:      make(chan *Entry),
+		remove:   make(chan EntryID),
+		pause:    make(chan pauseRequest),
 		stop:     make(chan struct{}),
 		snapshot: make(chan []*Entry),
 		running:  false,
@@ -96,37 +169,85 @@ type FuncJob func()
 
 func (f FuncJob) Run() { f() }
 
-// AddFunc adds a func to the Cron to be run on the given schedule.
-func (c *Cron) AddFunc(spec string, cmd func()) error {
-	return c.AddJob(spec, FuncJob(cmd))
+// Use appends the JobWrappers applied to every job added later,
+// they are outside the wrappers of the entry.
+func (c *Cron) Use(wrappers ...JobWrapper) {
+	c.runningMu.Lock()
+	c.wrappers = append(c.wrappers, wrappers...)
+	c.runningMu.Unlock()
 }
 
-// AddJob adds a Job to the Cron to be run on the given schedule.
-func (c *Cron) AddJob(spec string, cmd Job) error {
-	schedule, err := Parse(spec)
+// SetStore sets the Store persisting the last run times of the named entries.
+// It should be called before Start.
+func (c *Cron) SetStore(store Store) {
+	c.runningMu.Lock()
+	c.store = store
+	c.runningMu.Unlock()
+}
+
+// AddFunc adds a func to the Cron to be run on the given schedule,
+// configured with the optional EntryOptions, such as JobWrappers.
+// An opaque ID is returned that can be used to later remove it.
+func (c *Cron) AddFunc(spec string, cmd func(), opts ...EntryOption) (EntryID, error) {
+	return c.AddJob(spec, FuncJob(cmd), opts...)
+}
+
+// AddJob adds a Job to the Cron to be run on the given schedule,
+// configured with the optional EntryOptions, such as JobWrappers.
+// An opaque ID is returned that can be used to later remove it.
+func (c *Cron) AddJob(spec string, cmd Job, opts ...EntryOption) (EntryID, error) {
+	// The "H" tokens are hashed with the entry name, so that the named entries of the same spec spread.
+	schedule, err := defaultParser.WithHashKey(entryName(opts)).Parse(spec)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	return c.Schedule(schedule, cmd, opts...), nil
+}
+
+// entryName returns the name of the entry configured by opts.
+func entryName(opts []EntryOption) string {
+	var e Entry
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyEntry(&e)
+		}
 	}
-	c.Schedule(schedule, cmd)
-	return nil
+	return e.Name
 }
 
-// Schedule adds a Job to the Cron to be run on the given schedule.
-func (c *Cron) Schedule(schedule Schedule, cmd Job) {
+// Schedule adds a Job to the Cron to be run on the given schedule,
+// configured with the optional EntryOptions, such as JobWrappers.
+// An opaque ID is returned that can be used to later remove it.
+func (c *Cron) Schedule(schedule Schedule, cmd Job, opts ...EntryOption) EntryID {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	c.nextID++
 	entry := &Entry{
-		Schedule: schedule,
-		Job:      cmd,
+		ID:          c.nextID,
+		Schedule:    schedule,
+		Job:         cmd,
+		wrappers:    c.wrappers[:len(c.wrappers):len(c.wrappers)],
+		historySize: DefaultHistorySize,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyEntry(entry)
+		}
 	}
+	entry.wrappedJob = Chain(entry.wrappers...)(cmd)
+	entry.history = newHistory(entry.historySize)
 	if !c.running {
 		c.entries = append(c.entries, entry)
-		return
+	} else {
+		c.add <- entry
 	}
-
-	c.add <- entry
+	return entry.ID
 }
 
 // Entries returns a snapshot of the cron entries.
 func (c *Cron) Entries() []*Entry {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
 	if c.running {
 		c.snapshot <- nil
 		x := <-c.snapshot
@@ -135,6 +256,51 @@ func (c *Cron) Entries() []*Entry {
 	return c.entrySnapshot()
 }
 
+// Entry returns a snapshot of the given entry, or the zero Entry if it couldn't be found.
+func (c *Cron) Entry(id EntryID) Entry {
+	for _, entry := range c.Entries() {
+		if id == entry.ID {
+			return *entry
+		}
+	}
+	return Entry{}
+}
+
+// Remove removes an entry from being run in the future.
+// The job already running is not affected.
+func (c *Cron) Remove(id EntryID) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.remove <- id
+	} else {
+		c.removeEntry(id)
+	}
+}
+
+// Pause pauses an entry, so that it is not run until resumed.
+// The job already running is not affected.
+func (c *Cron) Pause(id EntryID) {
+	c.setPaused(id, true)
+}
+
+// Resume resumes a paused entry.
+// Its next activation time is computed from the time it is resumed,
+// so the activations missed while paused are not run.
+func (c *Cron) Resume(id EntryID) {
+	c.setPaused(id, false)
+}
+
+func (c *Cron) setPaused(id EntryID, paused bool) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.pause <- pauseRequest{id: id, paused: paused}
+	} else {
+		c.pauseEntry(id, paused, time.Time{})
+	}
+}
+
 // Location gets the time zone location
 func (c *Cron) Location() *time.Location {
 	return c.location
@@ -142,6 +308,8 @@ func (c *Cron) Location() *time.Location {
 
 // Start the cron scheduler in its own go-routine, or no-op if already started.
 func (c *Cron) Start() {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
 	if c.running {
 		return
 	}
@@ -151,23 +319,67 @@ func (c *Cron) Start() {
 
 // Run the cron scheduler, or no-op if already running.
 func (c *Cron) Run() {
+	c.runningMu.Lock()
 	if c.running {
+		c.runningMu.Unlock()
 		return
 	}
 	c.running = true
+	c.runningMu.Unlock()
 	c.run()
 }
 
-func (c *Cron) runWithRecovery(j Job) {
+// runScheduled runs the job of the entry occurrence scheduled at the time,
+// if it is claimed by this replica, and records the execution.
+func (c *Cron) runScheduled(e *Entry, scheduled time.Time) {
+	if c.locker != nil && e.Name != "" {
+		ok, err := c.locker.TryLock(e.Name, scheduled)
+		if err != nil {
+			c.logf("cron: failed to lock %s at %v: %v", e.Name, scheduled, err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	ev := Event{
+		EntryID:   e.ID,
+		Name:      e.Name,
+		Execution: Execution{Scheduled: scheduled, Start: time.Now()},
+	}
+	if c.hooks.OnStart != nil {
+		c.hooks.OnStart(ev)
+	}
+	c.runWithRecovery(e.wrappedJob, &ev.Execution)
+	ev.Duration = time.Since(ev.Start)
+	e.history.add(ev.Execution)
+	var callback func(Event)
+	switch {
+	case ev.Panic != nil:
+		callback = c.hooks.OnPanic
+	case ev.Err != nil:
+		callback = c.hooks.OnError
+	default:
+		callback = c.hooks.OnSuccess
+	}
+	if callback != nil {
+		callback(ev)
+	}
+}
+
+func (c *Cron) runWithRecovery(j Job, x *Execution) {
 	defer func() {
 		if r := recover(); r != nil {
 			const size = 64 << 10
 			buf := make([]byte, size)
 			buf = buf[:runtime.Stack(buf, false)]
+			x.Panic, x.Stack = r, string(buf)
 			c.logf("cron: panic running job: %v\n%s", r, buf)
 		}
 	}()
-	j.Run()
+	if x.Err = runJob(context.Background(), j); x.Err != nil {
+		c.logf("cron: error running job: %v", x.Err)
+	}
 }
 
 // Run the scheduler. this is private just due to the need to synchronize
@@ -176,6 +388,11 @@ func (c *Cron) run() {
 	// Figure out the next activation times for each entry.
 	now := c.now()
 	for _, entry := range c.entries {
+		c.catchUp(entry, now)
+		if entry.Paused {
+			entry.Next = time.Time{}
+			continue
+		}
 		entry.Next = entry.Schedule.Next(now)
 	}
 
@@ -201,17 +418,32 @@ func (c *Cron) run() {
 					if e.Next.After(now) || e.Next.IsZero() {
 						break
 					}
-					go c.runWithRecovery(e.Job)
+					go c.runScheduled(e, e.Next)
 					e.Prev = e.Next
 					e.Next = e.Schedule.Next(now)
+					if c.hooks.OnMissed != nil {
+						c.reportMissed(e, missedTimes(e.Schedule, e.Prev, now))
+					}
+					c.saveLastRun(e)
 				}
 
 			case newEntry := <-c.add:
 				timer.Stop()
 				now = c.now()
+				c.catchUp(newEntry, now)
 				newEntry.Next = newEntry.Schedule.Next(now)
 				c.entries = append(c.entries, newEntry)
 
+			case id := <-c.remove:
+				timer.Stop()
+				now = c.now()
+				c.removeEntry(id)
+
+			case req := <-c.pause:
+				timer.Stop()
+				now = c.now()
+				c.pauseEntry(req.id, req.paused, now)
+
 			case <-c.snapshot:
 				c.snapshot <- c.entrySnapshot()
 				continue
@@ -237,6 +469,8 @@ func (c *Cron) logf(format string, args ...interface{}) {
 
 // Stop stops the cron scheduler if it is running; otherwise it does nothing.
 func (c *Cron) Stop() {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
 	if !c.running {
 		return
 	}
@@ -248,16 +482,43 @@ func (c *Cron) Stop() {
 func (c *Cron) entrySnapshot() []*Entry {
 	entries := []*Entry{}
 	for _, e := range c.entries {
-		entries = append(entries, &Entry{
-			Schedule: e.Schedule,
-			Next:     e.Next,
-			Prev:     e.Prev,
-			Job:      e.Job,
-		})
+		entry := *e
+		entries = append(entries, &entry)
 	}
 	return entries
 }
 
+// removeEntry removes the entry with the given ID.
+func (c *Cron) removeEntry(id EntryID) {
+	for i, e := range c.entries {
+		if e.ID == id {
+			copy(c.entries[i:], c.entries[i+1:])
+			c.entries[len(c.entries)-1] = nil
+			c.entries = c.entries[:len(c.entries)-1]
+			return
+		}
+	}
+}
+
+// pauseEntry pauses or resumes the entry with the given ID.
+// If now is not zero, the scheduler is running and the next activation time is updated.
+func (c *Cron) pauseEntry(id EntryID, paused bool, now time.Time) {
+	for _, e := range c.entries {
+		if e.ID != id || e.Paused == paused {
+			continue
+		}
+		e.Paused = paused
+		if !now.IsZero() {
+			if paused {
+				e.Next = time.Time{}
+			} else {
+				e.Next = e.Schedule.Next(now)
+			}
+		}
+		return
+	}
+}
+
 // now returns current time in c location
 func (c *Cron) now() time.Time {
 	return time.Now().In(c.location).Round(time.Second)
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -359,3 +360,104 @@ func stop(cron *Cron) chan bool {
 	}()
 	return ch
 }
+
+// Add a job, remove it while running, expect it does not run.
+func TestRemoveWhileRunning(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := New()
+	cron.Start()
+	defer cron.Stop()
+	id, _ := cron.AddFunc("* * * * * ?", func() { wg.Done() })
+	cron.Remove(id)
+
+	select {
+	case <-time.After(OneSecond):
+	case <-wait(wg):
+		t.Error("expected removed job does not run")
+	}
+	if cron.Entry(id).Valid() {
+		t.Error("expected removed entry is not found")
+	}
+}
+
+// Remove a job before running, expect the others keep their IDs.
+func TestRemoveBeforeRunning(t *testing.T) {
+	cron := New()
+	id1, _ := cron.AddFunc("* * * * * ?", func() {})
+	id2, _ := cron.AddFunc("* * * * * ?", func() {})
+	id3 := cron.Schedule(Every(time.Minute), FuncJob(func() {}))
+	if id1 == id2 || id2 == id3 || id1 == 0 {
+		t.Fatalf("expected distinct IDs, got %d, %d, %d", id1, id2, id3)
+	}
+	cron.Remove(id2)
+	entries := cron.Entries()
+	if len(entries) != 2 || entries[0].ID != id1 || entries[1].ID != id3 {
+		t.Fatalf("unexpected entries after removal: %v", entries)
+	}
+}
+
+// Pause a job while running, expect it does not run until resumed.
+func TestPauseResume(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	cron := New()
+	id, _ := cron.AddFunc("* * * * * ?", func() {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+	cron.Pause(id)
+	cron.Start()
+	defer cron.Stop()
+
+	entry := cron.Entry(id)
+	if !entry.Paused || !entry.Next.IsZero() {
+		t.Fatalf("expected paused entry without next time, got %+v", entry)
+	}
+	<-time.After(OneSecond)
+	mu.Lock()
+	if calls != 0 {
+		t.Errorf("called %d times while paused, expected 0", calls)
+	}
+	mu.Unlock()
+
+	cron.Resume(id)
+	if entry = cron.Entry(id); entry.Paused || entry.Next.IsZero() {
+		t.Fatalf("expected resumed entry with next time, got %+v", entry)
+	}
+	<-time.After(2 * OneSecond)
+	mu.Lock()
+	if calls == 0 {
+		t.Error("expected resumed job runs")
+	}
+	mu.Unlock()
+}
+
+// The named entries of the same "H" spec spread, and are stable across the crons.
+func TestAddHashNamedEntries(t *testing.T) {
+	schedules := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("job-%d", i)
+		var first Schedule
+		for j := 0; j < 2; j++ {
+			cron := New()
+			id, err := cron.AddFunc("H H * * * *", func() {}, WithName(name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sched := cron.Entry(id).Schedule
+			if first == nil {
+				first = sched
+			} else if !reflect.DeepEqual(first, sched) {
+				t.Fatalf("expected the entry %s resolves the same schedule, got %v and %v", name, first, sched)
+			}
+		}
+		s := first.(*SpecSchedule)
+		schedules[fmt.Sprint(s.Second, s.Minute)] = true
+	}
+	if len(schedules) < 2 {
+		t.Errorf("expected the named entries spread, got %v", schedules)
+	}
+}
@@ -0,0 +1,542 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andeya/goutil/calendar"
+)
+
+// Language is the language of the description of a schedule.
+type Language int
+
+const (
+	// English describes in English, which is the default.
+	English Language = iota
+	// Chinese describes in Chinese.
+	Chinese
+)
+
+// Describe returns a human-readable sentence describing the spec accepted by Parse,
+// in English by default, e.g.
+//
+//	Describe("0 30 9 * * 1-5")          // "At 09:30:00, on Monday through Friday"
+//	Describe("0 30 9 * * 1-5", Chinese) // "周一至周五 09:30:00"
+//
+// NOTE:
+//
+//	The "H" tokens are described as the values they are resolved to.
+func Describe(spec string, lang ...Language) (string, error) {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return "", err
+	}
+	return DescribeSchedule(schedule, lang...), nil
+}
+
+// DescribeSchedule returns a human-readable sentence describing the schedule,
+// in English by default.
+// The schedule types unknown to this package are described by fmt.Sprint.
+func DescribeSchedule(schedule Schedule, lang ...Language) string {
+	var d = describer{lang: English}
+	if len(lang) > 0 {
+		d.lang = lang[0]
+	}
+	return d.schedule(schedule)
+}
+
+// NextN returns the next n activation times of the schedule after from, in order.
+// It stops early if the schedule can not be satisfied anymore, and returns nil if n <= 0.
+func NextN(schedule Schedule, from time.Time, n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+	var times = make([]time.Time, 0, n)
+	for t := from; len(times) < n; {
+		t = schedule.Next(t)
+		if t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+type describer struct {
+	lang Language
+}
+
+func (d describer) zh() bool { return d.lang == Chinese }
+
+func (d describer) schedule(schedule Schedule) string {
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		return d.spec(s)
+	case *LunarSchedule:
+		return d.lunar(s)
+	case ConstantDelaySchedule:
+		if d.zh() {
+			return "每" + s.Delay.String()
+		}
+		return "Every " + s.Delay.String()
+	case *LocationSchedule:
+		if d.zh() {
+			return d.schedule(s.Schedule) + "（" + s.Location.String() + "）"
+		}
+		return d.schedule(s.Schedule) + " (" + s.Location.String() + ")"
+	case *JitterSchedule:
+		if d.zh() {
+			return d.schedule(s.Schedule) + "，随机延迟至多" + s.MaxJitter.String()
+		}
+		return d.schedule(s.Schedule) + ", delayed randomly by up to " + s.MaxJitter.String()
+	default:
+		return fmt.Sprint(schedule)
+	}
+}
+
+func (d describer) spec(s *SpecSchedule) string {
+	var (
+		month     = analyzeField(s.Month, months)
+		dayOfMon  = analyzeField(s.Dom, dom)
+		dayOfWeek = analyzeField(s.Dow, dow)
+		clock     = d.clock(s.Second, s.Minute, s.Hour)
+		parts     []string
+	)
+	// The day of month and the day of week are ORed only if neither is a star.
+	domRestricted := dayOfMon.kind != kindAll && s.Dow&starBit > 0
+	dowRestricted := dayOfWeek.kind != kindAll && s.Dom&starBit > 0
+	if s.Dom&starBit == 0 && s.Dow&starBit == 0 {
+		domRestricted, dowRestricted = true, true
+	}
+	if d.zh() {
+		var date string
+		switch {
+		case month.kind != kindAll:
+			date = d.listZh(month, "月", "个月", nil)
+			if domRestricted {
+				date += d.domZh(dayOfMon, s.ext)
+			}
+		case domRestricted:
+			date = "每月" + d.domZh(dayOfMon, s.ext)
+		}
+		if dowRestricted {
+			if domRestricted {
+				date += "或"
+			}
+			if s.ext.hasDow() && month.kind == kindAll {
+				date += "每月"
+			}
+			date += d.dowZh(dayOfWeek, s.ext)
+		}
+		if date == "" && clock.fixed {
+			date = "每天"
+		}
+		if date != "" {
+			parts = append(parts, date)
+		}
+		parts = append(parts, clock.text)
+		return strings.Join(parts, " ")
+	}
+
+	parts = append(parts, clock.text)
+	switch {
+	case domRestricted && dowRestricted:
+		parts = append(parts, d.domEn(dayOfMon, s.ext)+" or "+d.dowEn(dayOfWeek, s.ext))
+	case domRestricted:
+		parts = append(parts, d.domEn(dayOfMon, s.ext))
+	case dowRestricted:
+		parts = append(parts, d.dowEn(dayOfWeek, s.ext))
+	}
+	if month.kind != kindAll {
+		if month.kind == kindStep {
+			parts = append(parts, d.stepEn(month, "month", "months", monthEn))
+		} else {
+			parts = append(parts, "in "+d.listEn(month.values, monthEn))
+		}
+	}
+	return capitalize(strings.Join(parts, ", "))
+}
+
+func (d describer) lunar(s *LunarSchedule) string {
+	var (
+		month    = analyzeField(s.Month, lunarMonths)
+		leap     = analyzeField(s.LeapMonth, lunarMonths)
+		dayOfMon = analyzeField(s.Dom, lunarDom)
+		clock    = d.clock(s.Second, s.Minute, s.Hour)
+	)
+	allMonths := month.kind == kindAll && leap.kind == kindAll
+	if d.zh() {
+		var months string
+		if allMonths {
+			months = "每月"
+		} else {
+			var names []string
+			for _, m := range month.values {
+				names = append(names, calendar.LunarMonthString(int(m), false))
+			}
+			for _, m := range leap.values {
+				names = append(names, calendar.LunarMonthString(int(m), true))
+			}
+			months = strings.Join(names, "、")
+		}
+		var days string
+		if dayOfMon.kind == kindAll {
+			days = "每天"
+		} else {
+			var names []string
+			for _, v := range dayOfMon.values {
+				names = append(names, calendar.LunarDayString(int(v)))
+			}
+			days = strings.Join(names, "、")
+		}
+		return "农历" + months + days + " " + clock.text
+	}
+
+	parts := []string{clock.text}
+	if dayOfMon.kind != kindAll {
+		parts = append(parts, "on lunar "+d.dayEn(dayOfMon))
+	} else {
+		parts = append(parts, "every lunar day")
+	}
+	if !allMonths {
+		var names []string
+		for _, m := range month.values {
+			names = append(names, "month "+strconv.Itoa(int(m)))
+		}
+		for _, m := range leap.values {
+			names = append(names, "leap month "+strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "in lunar "+joinEn(names))
+	}
+	return capitalize(strings.Join(parts, ", "))
+}
+
+type clockText struct {
+	text  string
+	fixed bool // once a day
+}
+
+// clock describes the time of day.
+func (d describer) clock(second, minute, hour uint64) clockText {
+	var (
+		s = analyzeField(second, seconds)
+		m = analyzeField(minute, minutes)
+		h = analyzeField(hour, hours)
+	)
+	if s.kind == kindSingle && m.kind == kindSingle && (h.kind == kindSingle || h.kind != kindAll && len(h.values) <= 4) {
+		var times []string
+		for _, v := range h.values {
+			times = append(times, fmt.Sprintf("%02d:%02d:%02d", v, m.values[0], s.values[0]))
+		}
+		if d.zh() {
+			return clockText{text: strings.Join(times, "、"), fixed: h.kind == kindSingle}
+		}
+		return clockText{text: "at " + joinEn(times), fixed: h.kind == kindSingle}
+	}
+
+	type unit struct {
+		field                    field
+		singular, plural, atZh   string
+		everyZh, stepZh, startZh string
+	}
+	units := []unit{
+		{s, "second", "seconds", "秒", "每秒", "秒", "第"},
+		{m, "minute", "minutes", "分", "每分钟", "分钟", "第"},
+		{h, "hour", "hours", "点", "每小时", "小时", ""},
+	}
+	var parts []string
+	var lastKind fieldKind = -1
+	for i, u := range units {
+		f := u.field
+		// The second 0 is implied, unless everything else is every.
+		if i == 0 && f.kind == kindSingle && f.values[0] == 0 && (m.kind != kindAll || h.kind != kindAll) {
+			continue
+		}
+		// Every larger unit is implied by the smaller every or step.
+		if f.kind == kindAll && (lastKind == kindAll || lastKind == kindStep) {
+			continue
+		}
+		lastKind = f.kind
+		if d.zh() {
+			switch f.kind {
+			case kindAll:
+				parts = append(parts, u.everyZh)
+			case kindStep:
+				parts = append(parts, d.stepZh(f, u.startZh, u.atZh, u.stepZh))
+			default:
+				parts = append(parts, u.startZh+d.listZh(f, u.atZh, u.stepZh, nil))
+			}
+			continue
+		}
+		switch f.kind {
+		case kindAll:
+			parts = append(parts, "every "+u.singular)
+		case kindStep:
+			parts = append(parts, d.stepEn(f, u.singular, u.plural, nil))
+		case kindSingle:
+			parts = append(parts, "at "+u.singular+" "+strconv.Itoa(int(f.values[0])))
+		default:
+			parts = append(parts, "at "+u.plural+" "+d.listEn(f.values, nil))
+		}
+	}
+	if d.zh() {
+		// From the larger unit to the smaller one.
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		return clockText{text: strings.Join(parts, "")}
+	}
+	return clockText{text: strings.Join(parts, ", ")}
+}
+
+func (d describer) domEn(f field, ext *specExt) string {
+	var items []string
+	if ext.hasDom() {
+		for _, n := range ext.lastDom {
+			if n == 0 {
+				items = append(items, "the last day")
+			} else {
+				items = append(items, strconv.Itoa(n)+" days before the last day")
+			}
+		}
+		for _, n := range ext.nearestWeekdays {
+			items = append(items, "the weekday nearest day "+strconv.Itoa(n))
+		}
+		if ext.lastWeekday {
+			items = append(items, "the last weekday")
+		}
+	}
+	if len(f.values) == 0 {
+		return "on " + joinEn(items) + " of the month"
+	}
+	if f.kind == kindStep {
+		s := d.stepEn(f, "day", "days", nil) + " of the month"
+		if len(items) > 0 {
+			s += " and on " + joinEn(items) + " of the month"
+		}
+		return s
+	}
+	return "on " + joinEn(append([]string{d.dayEn(f)}, items...)) + " of the month"
+}
+
+func (d describer) dayEn(f field) string {
+	if f.kind == kindSingle {
+		return "day " + strconv.Itoa(int(f.values[0]))
+	}
+	return "days " + d.listEn(f.values, nil)
+}
+
+func (d describer) dowEn(f field, ext *specExt) string {
+	var items []string
+	if len(f.values) > 0 {
+		items = append(items, d.listEn(f.values, weekdayEn))
+	}
+	if ext.hasDow() {
+		for v := dow.min; v <= dow.max; v++ {
+			if 1<<v&ext.lastDow > 0 {
+				items = append(items, "the last "+weekdayEn[v]+" of the month")
+			}
+		}
+		for _, dn := range ext.nthDow {
+			items = append(items, "the "+ordinalEn[dn[1]]+" "+weekdayEn[dn[0]]+" of the month")
+		}
+	}
+	return "on " + joinEn(items)
+}
+
+// domZh describes the day of month field, e.g. "1日、最后一天".
+func (d describer) domZh(f field, ext *specExt) string {
+	var items []string
+	if len(f.values) > 0 {
+		items = append(items, d.listZh(f, "日", "天", nil))
+	}
+	if ext.hasDom() {
+		for _, n := range ext.lastDom {
+			if n == 0 {
+				items = append(items, "最后一天")
+			} else {
+				items = append(items, "倒数第"+strconv.Itoa(n+1)+"天")
+			}
+		}
+		for _, n := range ext.nearestWeekdays {
+			items = append(items, strconv.Itoa(n)+"日最近的工作日")
+		}
+		if ext.lastWeekday {
+			items = append(items, "最后一个工作日")
+		}
+	}
+	return strings.Join(items, "、")
+}
+
+// dowZh describes the day of week field, e.g. "周一、第3个周五".
+func (d describer) dowZh(f field, ext *specExt) string {
+	var items []string
+	if len(f.values) > 0 {
+		items = append(items, d.listZh(f, "", "天", weekdayZh))
+	}
+	if ext.hasDow() {
+		for v := dow.min; v <= dow.max; v++ {
+			if 1<<v&ext.lastDow > 0 {
+				items = append(items, "最后一个"+weekdayZh[v])
+			}
+		}
+		for _, dn := range ext.nthDow {
+			items = append(items, "第"+strconv.Itoa(dn[1])+"个"+weekdayZh[dn[0]])
+		}
+	}
+	return strings.Join(items, "、")
+}
+
+// stepEn describes the step field, e.g. "every 15 minutes starting at minute 5".
+func (d describer) stepEn(f field, singular, plural string, names []string) string {
+	s := "every " + strconv.Itoa(int(f.step)) + " " + plural
+	if f.values[0] != f.min {
+		s += " starting at " + valueName(f.values[0], names, singular+" ")
+	}
+	return s
+}
+
+// stepZh describes the step field, e.g. "从第5分起每15分钟".
+func (d describer) stepZh(f field, prefix, at, unit string) string {
+	s := "每" + strconv.Itoa(int(f.step)) + unit
+	if f.values[0] != f.min {
+		s = "从" + prefix + strconv.Itoa(int(f.values[0])) + at + "起" + s
+	}
+	return s
+}
+
+// listEn describes the values, e.g. "1 through 5 and 10".
+func (d describer) listEn(values []uint, names []string) string {
+	var items []string
+	for _, r := range compressRanges(values) {
+		if r[0] == r[1] {
+			items = append(items, valueName(r[0], names, ""))
+		} else {
+			items = append(items, valueName(r[0], names, "")+" through "+valueName(r[1], names, ""))
+		}
+	}
+	return joinEn(items)
+}
+
+// listZh describes the field, e.g. "1至5、10日", or "每2天" for the step field.
+func (d describer) listZh(f field, suffix, stepUnit string, names []string) string {
+	if f.kind == kindStep && names == nil {
+		return d.stepZh(f, "", suffix, stepUnit)
+	}
+	var items []string
+	for _, r := range compressRanges(f.values) {
+		if r[0] == r[1] {
+			items = append(items, valueName(r[0], names, ""))
+		} else {
+			items = append(items, valueName(r[0], names, "")+"至"+valueName(r[1], names, ""))
+		}
+	}
+	return strings.Join(items, "、") + suffix
+}
+
+var (
+	monthEn = []string{"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	weekdayEn = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	weekdayZh = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+	ordinalEn = []string{"", "first", "second", "third", "fourth", "fifth"}
+)
+
+func valueName(v uint, names []string, prefix string) string {
+	if int(v) < len(names) {
+		return names[v]
+	}
+	return prefix + strconv.Itoa(int(v))
+}
+
+type fieldKind int
+
+const (
+	kindAll fieldKind = iota
+	kindSingle
+	kindStep
+	kindList
+)
+
+// field is the analyzed bits of a field.
+type field struct {
+	kind     fieldKind
+	values   []uint
+	step     uint
+	min, max uint
+}
+
+// analyzeField classifies the bits within the bounds.
+func analyzeField(bits uint64, r bounds) field {
+	f := field{min: r.min, max: r.max}
+	for v := r.min; v <= r.max; v++ {
+		if 1<<v&bits > 0 {
+			f.values = append(f.values, v)
+		}
+	}
+	switch n := uint(len(f.values)); {
+	case n == r.max-r.min+1:
+		f.kind = kindAll
+	case n == 1:
+		f.kind = kindSingle
+	case n > 2:
+		// An arithmetic progression to the end of the bounds, e.g. "*/15" or "5/15".
+		step := f.values[1] - f.values[0]
+		f.kind = kindStep
+		for i := 2; i < len(f.values); i++ {
+			if f.values[i]-f.values[i-1] != step {
+				f.kind = kindList
+				break
+			}
+		}
+		if step == 1 || f.values[n-1]+step <= r.max || f.values[0] >= r.min+step {
+			f.kind = kindList
+		}
+		if f.kind == kindStep {
+			f.step = step
+		}
+	default:
+		f.kind = kindList
+	}
+	return f
+}
+
+// compressRanges groups the sorted values into the ranges of at least 3 consecutive values.
+func compressRanges(values []uint) [][2]uint {
+	var ranges [][2]uint
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			ranges = append(ranges, [2]uint{values[i], values[j]})
+		} else {
+			for k := i; k <= j; k++ {
+				ranges = append(ranges, [2]uint{values[k], values[k]})
+			}
+		}
+		i = j + 1
+	}
+	return ranges
+}
+
+// joinEn joins the items like "a, b and c".
+func joinEn(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
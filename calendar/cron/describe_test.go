@@ -0,0 +1,103 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"0 30 9 * * 1-5", "At 09:30:00, on Monday through Friday", "周一至周五 09:30:00"},
+		{"* * * * * *", "Every second", "每秒"},
+		{"0 */15 * * * *", "Every 15 minutes", "每15分钟"},
+		{"5/15 * * * * *", "Every 15 seconds starting at second 5", "从第5秒起每15秒"},
+		{"30 * * * * *", "At second 30, every minute", "每分钟第30秒"},
+		{"0 0 9-17 * * 1-5", "At minute 0, at hours 9 through 17, on Monday through Friday", "周一至周五 9至17点第0分"},
+		{"0 0 9,18 1,15 * *", "At 09:00:00 and 18:00:00, on days 1 and 15 of the month", "每月1、15日 09:00:00、18:00:00"},
+		{"0 0 0 1 1 *", "At 00:00:00, on day 1 of the month, in January", "1月1日 00:00:00"},
+		{"0 0 0 1 * 1", "At 00:00:00, on day 1 of the month or on Monday", "每月1日或周一 00:00:00"},
+		{"0 0 12 ? JAN-MAR MON,WED,FRI", "At 12:00:00, on Monday, Wednesday and Friday, in January through March", "1至3月周一、周三、周五 12:00:00"},
+		{"@daily", "At 00:00:00", "每天 00:00:00"},
+		{"@every 1h30m", "Every 1h30m0s", "每1h30m0s"},
+		{"@lunar 0 0 9 15 8", "At 09:00:00, on lunar day 15, in lunar month 8", "农历八月十五 09:00:00"},
+		{"@lunar 0 0 9 1 4,leap4", "At 09:00:00, on lunar day 1, in lunar month 4 and leap month 4", "农历四月、闰四月初一 09:00:00"},
+		{"CRON_TZ=Asia/Tokyo 0 0 6 * * *", "At 06:00:00 (Asia/Tokyo)", "每天 06:00:00（Asia/Tokyo）"},
+	}
+	for _, c := range tests {
+		en, err := Describe(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if en != c.en {
+			t.Errorf("%q: (expected) %q != %q (actual)", c.spec, c.en, en)
+		}
+		if zh, _ := Describe(c.spec, Chinese); zh != c.zh {
+			t.Errorf("%q: (expected) %q != %q (actual)", c.spec, c.zh, zh)
+		}
+	}
+	if _, err := Describe("* * *"); err == nil {
+		t.Error("expected an error for the invalid spec")
+	}
+}
+
+func TestDescribeExtended(t *testing.T) {
+	p := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | Extended)
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"0 0 0 L * ?", "At 00:00:00, on the last day of the month", "每月最后一天 00:00:00"},
+		{"0 0 0 1,L-2 * ?", "At 00:00:00, on day 1 and 2 days before the last day of the month", "每月1日、倒数第3天 00:00:00"},
+		{"0 0 9 LW 12 ?", "At 09:00:00, on the last weekday of the month, in December", "12月最后一个工作日 09:00:00"},
+		{"0 0 9 15W * ?", "At 09:00:00, on the weekday nearest day 15 of the month", "每月15日最近的工作日 09:00:00"},
+		{"0 0 9 ? * 5L", "At 09:00:00, on the last Friday of the month", "每月最后一个周五 09:00:00"},
+		{"0 0 9 ? * 1,5#3", "At 09:00:00, on Monday and the third Friday of the month", "每月周一、第3个周五 09:00:00"},
+	}
+	for _, c := range tests {
+		sched, err := p.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if en := DescribeSchedule(sched); en != c.en {
+			t.Errorf("%q: (expected) %q != %q (actual)", c.spec, c.en, en)
+		}
+		if zh := DescribeSchedule(sched, Chinese); zh != c.zh {
+			t.Errorf("%q: (expected) %q != %q (actual)", c.spec, c.zh, zh)
+		}
+	}
+}
+
+func TestNextN(t *testing.T) {
+	from := getTime("Mon Jul 9 14:45 2012")
+	sched, _ := Parse("0 0 9 * * 1-5")
+	expected := []string{"Tue Jul 10 09:00 2012", "Wed Jul 11 09:00 2012", "Thu Jul 12 09:00 2012", "Fri Jul 13 09:00 2012", "Mon Jul 16 09:00 2012"}
+	actual := NextN(sched, from, 5)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d times, got %v", len(expected), actual)
+	}
+	for i, e := range expected {
+		if !actual[i].Equal(getTime(e)) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, getTime(e), actual[i])
+		}
+	}
+
+	actual = NextN(Every(15*time.Minute), from, 3)
+	for i, e := range []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012", "Mon Jul 9 15:30 2012"} {
+		if !actual[i].Equal(getTime(e)) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, getTime(e), actual[i])
+		}
+	}
+
+	if actual = NextN(new(ZeroSchedule), from, 3); len(actual) != 0 {
+		t.Errorf("expected no time for the unsatisfiable schedule, got %v", actual)
+	}
+
+	for _, n := range []int{0, -1} {
+		if actual = NextN(sched, from, n); actual != nil {
+			t.Errorf("expected nil for n=%d, got %v", n, actual)
+		}
+	}
+}
@@ -15,11 +15,16 @@ them in their own goroutines.
 	// Funcs are invoked in their own goroutine, asynchronously.
 	...
 	// Funcs may also be added to a running Cron
-	c.AddFunc("@daily", func() { fmt.Println("Every day") })
+	id, _ := c.AddFunc("@daily", func() { fmt.Println("Every day") })
 	..
 	// Inspect the cron job entries' next and previous run times.
 	inspect(c.Entries())
 	..
+	// Entries may be paused, resumed and removed by their IDs.
+	c.Pause(id)
+	c.Resume(id)
+	c.Remove(id)
+	..
 	c.Stop()  // Stop the scheduler (does not stop any jobs already running).
 
 CRON Expression Format
@@ -70,6 +75,31 @@ Question mark ( ? )
 Question mark may be used instead of '*' for leaving either day-of-month or
 day-of-week blank.
 
+L, W and Hash ( # )
+
+With the Extended parse option, the day fields accept the Quartz extensions:
+
+	Field        | Item   | Description
+	-----        | ----   | -----------
+	Day of month | L      | The last day of month
+	Day of month | L-3    | The 3rd day before the last day of month
+	Day of month | 15W    | The weekday (Monday to Friday) nearest to the 15th, within the month
+	Day of month | LW     | The last weekday of month
+	Day of week  | L      | The last day of week, i.e. Saturday
+	Day of week  | 5L     | The last Friday of month, also FRIL
+	Day of week  | 5#3    | The 3rd Friday of month, also FRI#3
+
+They may be mixed with the other items in the list, e.g. "1,L". The default
+parsers do not accept them, so use a parser with the Extended option:
+
+	p := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.DowOptional | cron.Descriptor | cron.Extended)
+	sched, err := p.Parse("0 0 18 LW * ?")
+	if err == nil {
+		c.Schedule(sched, job)
+	}
+
+The extensions are not supported by LunarNext.
+
 Predefined schedules
 
 You may use one of several pre-defined schedules in place of a cron expression.
@@ -99,10 +129,116 @@ Note: The interval does not take the job runtime into account.  For example,
 if a job takes 3 minutes to run, and it is scheduled to run every 5 minutes,
 it will have only 2 minutes of idle time between each run.
 
+Observability
+
+Each entry records its recent executions in a ring buffer, with the start time,
+duration, error, and the recovered panic and stack. The size is
+DefaultHistorySize, or the one given by WithHistorySize.
+
+	for _, x := range c.Entry(id).History() {
+		fmt.Println(x.Start, x.Duration, x.Err, x.Panic)
+	}
+
+The callbacks set by Cron.SetHooks are called on the executions of all the
+entries: OnStart, OnSuccess, OnError, OnPanic and OnMissed.
+
+Persistence
+
+A Store set by Cron.SetStore persists the last run times of the entries named by
+WithName, so that the runs missed while the process is down can be caught up on
+Start, according to the MisfirePolicy of the entry: MisfireSkip (default),
+MisfireFireOnce or MisfireFireAll.
+
+	store, err := cron.NewFileStore("/var/lib/myapp/cron.json")
+	..
+	c.SetStore(store)
+	c.AddFunc("@daily", report, cron.WithName("report"), cron.WithMisfirePolicy(cron.MisfireFireOnce))
+
+Replicas
+
+When the replicas of a service run the same entries, a Locker set by
+Cron.SetLocker is consulted before running a named entry, so that only one of
+the replicas runs each scheduled occurrence. FileLocker coordinates the
+processes by flock(2) files in a shared directory, and MemoryLocker coordinates
+the Crons in the same process.
+
+	locker, err := cron.NewFileLocker("/var/lock/myapp")
+	..
+	c.SetLocker(locker)
+
+Lunar calendar
+
+A spec prefixed by "@lunar" activates on the days of the Chinese lunar calendar.
+It has the fields of the parser except the day of week, and the day of month
+(1-30) and month (1-12) are lunar ones. A month prefixed by "leap" matches the
+leap month, e.g. "leap4" is 闰四月, and "*" matches all the months including the
+leap ones.
+
+	c.AddFunc("@lunar 0 0 9 15 8", func() { fmt.Println("Mid-Autumn Festival") })
+	c.AddFunc("@lunar 0 0 9 1 *", func() { fmt.Println("Every lunar 1st of month") })
+	c.AddFunc("@lunar 0 0 9 1 4,leap4", func() { fmt.Println("Both 四月 and 闰四月") })
+
+The lunar date is the one of the local solar date, and the days missing in a
+month, e.g. the 30th of a 29-day month, are skipped.
+
+Job wrappers
+
+A JobWrapper decorates a job with some behavior. The wrappers given to
+AddFunc, AddJob or Schedule apply to that entry, and the ones given to Cron.Use
+apply to every entry added later.
+
+	c.Use(cron.SkipIfStillRunning())
+	c.AddJob("@every 1m", job, cron.Timeout(30*time.Second), cron.Retry(3, time.Second))
+
+The built-in wrappers are SkipIfStillRunning, DelayIfStillRunning, Timeout and
+Retry. Timeout and Retry work with ContextJob, which accepts a context and
+reports an error.
+
+Spreading the load
+
+The same spec running on many hosts activates at the same instant. The "H" token
+in the Jenkins style resolves to a value within the field, so the activations
+spread over the hosts:
+
+	H           a value in the field, e.g. "H" in the minutes field is like "17"
+	H/step      every step starting at a value less than step, e.g. "H/15"
+	H(a-b)      a value within a-b, e.g. "H(0-5)" in the hours field
+	H(a-b)/step every step within a-b
+
+Without the explicit range, "H" in the day of month field resolves within 1-28,
+so that the day exists in every month.
+
+"H" is resolved by the hash of the spec, so the same spec is stable across the
+restarts, and the different specs spread. Cron.AddFunc and Cron.AddJob also hash
+the entry name set by WithName, so the named entries of the same spec spread too:
+
+	c.AddFunc("H H * * *", backupDB, cron.WithName("backup-db"))
+	c.AddFunc("H H * * *", backupLogs, cron.WithName("backup-logs"))
+
+The parser returned by Parser.WithHashKey also hashes the key, e.g. the host name,
+so that the same spec spreads over the hosts but is stable on each host:
+
+	p := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow).WithHashKey(hostname)
+	sched, err := p.Parse("H H * * *")
+	if err == nil {
+		c.Schedule(sched, job)
+	}
+
+Jitter delays each activation of a schedule by a random duration instead:
+
+	c.Schedule(cron.Jitter(cron.Every(time.Hour), time.Minute), job)
+
 Time zones
 
-All interpretation and scheduling is done in the machine's local time zone (as
-provided by the Go time package (http://www.golang.org/pkg/time).
+By default, all interpretation and scheduling is done in the machine's local
+time zone (as provided by the Go time package (http://www.golang.org/pkg/time),
+or the one given to NewWithLocation.
+
+The time zone of an individual spec can be set by prefixing it with "CRON_TZ="
+or "TZ=" and the location name:
+
+	# Runs at 6am in Asia/Tokyo
+	c.AddFunc("CRON_TZ=Asia/Tokyo 0 0 6 * * ?", func() {})
 
 Be aware that jobs scheduled during daylight-savings leap-ahead transitions will
 not be run!
@@ -0,0 +1,178 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// specExt holds the Quartz extensions of the day of month and day of week fields,
+// which are enabled by the Extended ParseOption.
+type specExt struct {
+	// lastDom are the offsets from the last day of month, e.g. 0 for "L" and 3 for "L-3".
+	lastDom []int
+	// nearestWeekdays are the days of month whose nearest weekday matches, e.g. 15 for "15W".
+	nearestWeekdays []int
+	// lastWeekday is true for "LW", the last weekday of month.
+	lastWeekday bool
+	// lastDow are the bits of the days of week, whose last one in the month matches, e.g. "5L".
+	lastDow uint64
+	// nthDow are the days of week and their ordinals in the month, e.g. {5, 3} for "5#3".
+	nthDow [][2]int
+}
+
+// parseDomExt extracts the extended items of the day of month field,
+// and returns the remaining items.
+//
+//	L       the last day of month
+//	L-n     the n-th day before the last day of month
+//	nW      the weekday (Monday to Friday) nearest to the day n, within the month,
+//	        which never matches in the months without the day n
+//	LW      the last weekday of month
+func parseDomExt(field string, ext *specExt) (string, error) {
+	var rest []string
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "L":
+			ext.lastDom = append(ext.lastDom, 0)
+		case upper == "LW":
+			ext.lastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			n, err := mustParseInt(item[2:])
+			if err != nil {
+				return "", err
+			}
+			if n >= dom.max {
+				return "", fmt.Errorf("Offset from the last day of month (%d) above maximum (%d): %s", n, dom.max-1, item)
+			}
+			ext.lastDom = append(ext.lastDom, int(n))
+		case strings.HasSuffix(upper, "W"):
+			n, err := mustParseInt(item[:len(item)-1])
+			if err != nil {
+				return "", err
+			}
+			if n < dom.min || n > dom.max {
+				return "", fmt.Errorf("Day of month (%d) out of bounds (%d-%d): %s", n, dom.min, dom.max, item)
+			}
+			ext.nearestWeekdays = append(ext.nearestWeekdays, int(n))
+		default:
+			rest = append(rest, item)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+// parseDowExt extracts the extended items of the day of week field,
+// and returns the remaining items.
+//
+//	L       the last day of week, i.e. Saturday
+//	dL      the last day of week d in the month, e.g. "5L" or "FRIL"
+//	d#n     the n-th day of week d in the month, e.g. "5#3" or "FRI#3"
+func parseDowExt(field string, ext *specExt) (string, error) {
+	var rest []string
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "L":
+			rest = append(rest, strconv.Itoa(int(dow.max)))
+		case len(upper) > 1 && strings.HasSuffix(upper, "L"):
+			d, err := parseIntOrName(item[:len(item)-1], dow.names)
+			if err != nil {
+				return "", err
+			}
+			if d > dow.max {
+				return "", fmt.Errorf("Day of week (%d) above maximum (%d): %s", d, dow.max, item)
+			}
+			ext.lastDow |= 1 << d
+		case strings.Contains(item, "#"):
+			i := strings.IndexByte(item, '#')
+			d, err := parseIntOrName(item[:i], dow.names)
+			if err != nil {
+				return "", err
+			}
+			n, err := mustParseInt(item[i+1:])
+			if err != nil {
+				return "", err
+			}
+			if d > dow.max || n < 1 || n > 5 {
+				return "", fmt.Errorf("Day of week (%d) should be in 0-6 and ordinal (%d) in 1-5: %s", d, n, item)
+			}
+			ext.nthDow = append(ext.nthDow, [2]int{int(d), int(n)})
+		default:
+			rest = append(rest, item)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+func (ext *specExt) hasDom() bool {
+	return ext != nil && (len(ext.lastDom) > 0 || len(ext.nearestWeekdays) > 0 || ext.lastWeekday)
+}
+
+func (ext *specExt) hasDow() bool {
+	return ext != nil && (ext.lastDow != 0 || len(ext.nthDow) > 0)
+}
+
+// domMatches returns true if the day of month of t matches the extended items.
+func (ext *specExt) domMatches(t time.Time) bool {
+	if ext == nil {
+		return false
+	}
+	day, last := t.Day(), daysIn(t)
+	for _, n := range ext.lastDom {
+		if day == last-n {
+			return true
+		}
+	}
+	if ext.lastWeekday && day == nearestWeekday(t, last, last) {
+		return true
+	}
+	for _, n := range ext.nearestWeekdays {
+		if n <= last && day == nearestWeekday(t, n, last) {
+			return true
+		}
+	}
+	return false
+}
+
+// dowMatches returns true if the day of week of t matches the extended items.
+func (ext *specExt) dowMatches(t time.Time) bool {
+	if ext == nil {
+		return false
+	}
+	weekday := int(t.Weekday())
+	if 1<<uint(weekday)&ext.lastDow > 0 && t.Day()+7 > daysIn(t) {
+		return true
+	}
+	for _, dn := range ext.nthDow {
+		if dn[0] == weekday && (t.Day()-1)/7+1 == dn[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn returns the number of days in the month of t.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the day of month of the weekday nearest to the day n
+// in the month of t, without crossing the month.
+func nearestWeekday(t time.Time, n, last int) int {
+	switch time.Date(t.Year(), t.Month(), n, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if n == 1 {
+			return n + 2
+		}
+		return n - 1
+	case time.Sunday:
+		if n == last {
+			return n - 2
+		}
+		return n + 1
+	}
+	return n
+}
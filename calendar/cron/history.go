@@ -0,0 +1,139 @@
+package cron
+
+import (
+	"sync"
+	"time"
+)
+
+// Execution records an execution of an entry.
+type Execution struct {
+	// Scheduled is the activation time of the execution.
+	Scheduled time.Time
+	// Start is the time the job started.
+	Start time.Time
+	// Duration is how long the job ran.
+	Duration time.Duration
+	// Err is the error returned by the ContextJob.
+	Err error
+	// Panic is the value recovered from the panic of the job, or nil.
+	Panic interface{}
+	// Stack is the stack trace of the panic.
+	Stack string
+}
+
+// Panicked returns true if the job panicked.
+func (x Execution) Panicked() bool { return x.Panic != nil }
+
+// Event describes an execution, or the missed executions, of an entry.
+type Event struct {
+	EntryID EntryID
+	Name    string
+	Execution
+	// Missed is the number of the missed executions since Scheduled, only for Hooks.OnMissed.
+	Missed int
+}
+
+// Hooks are the callbacks on the executions of the entries.
+// NOTE:
+//
+//	The callbacks are called in their own goroutines or the goroutine of the job,
+//	so they must be safe for concurrent use;
+//	The nil callbacks are ignored.
+type Hooks struct {
+	// OnStart is called before the job runs, with Scheduled and Start set.
+	OnStart func(Event)
+	// OnSuccess is called after the job returns without error.
+	OnSuccess func(Event)
+	// OnError is called after the ContextJob returns an error.
+	OnError func(Event)
+	// OnPanic is called after the job panics.
+	OnPanic func(Event)
+	// OnMissed is called when some executions are missed,
+	// e.g. while the process is down or the scheduler wakes up late.
+	OnMissed func(Event)
+}
+
+// DefaultHistorySize is the default number of the recent executions recorded for each entry.
+var DefaultHistorySize = 10
+
+// WithHistorySize sets the number of the recent executions recorded for the entry.
+// If n <= 0, no execution is recorded.
+func WithHistorySize(n int) EntryOption {
+	return entryOptionFunc(func(e *Entry) { e.historySize = n })
+}
+
+// SetHooks sets the callbacks on the executions of the entries.
+// It should be called before Start.
+func (c *Cron) SetHooks(hooks Hooks) {
+	c.runningMu.Lock()
+	c.hooks = hooks
+	c.runningMu.Unlock()
+}
+
+// History returns the recent executions of the entry, the oldest first.
+func (e Entry) History() []Execution {
+	if e.history == nil {
+		return nil
+	}
+	return e.history.list()
+}
+
+// history is a ring buffer of the recent executions.
+type history struct {
+	mu   sync.Mutex
+	buf  []Execution
+	next int
+	full bool
+}
+
+func newHistory(size int) *history {
+	if size <= 0 {
+		return nil
+	}
+	return &history{buf: make([]Execution, size)}
+}
+
+func (h *history) add(x Execution) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.buf[h.next] = x
+	h.next++
+	if h.next == len(h.buf) {
+		h.next = 0
+		h.full = true
+	}
+	h.mu.Unlock()
+}
+
+func (h *history) list() []Execution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]Execution(nil), h.buf[:h.next]...)
+	}
+	return append(append([]Execution(nil), h.buf[h.next:]...), h.buf[:h.next]...)
+}
+
+// reportMissed calls Hooks.OnMissed for the missed activation times of the entry.
+func (c *Cron) reportMissed(e *Entry, missed []time.Time) {
+	if len(missed) == 0 || c.hooks.OnMissed == nil {
+		return
+	}
+	go c.hooks.OnMissed(Event{
+		EntryID:   e.ID,
+		Name:      e.Name,
+		Execution: Execution{Scheduled: missed[0]},
+		Missed:    len(missed),
+	})
+}
+
+// missedTimes returns the activation times of the schedule in (from, to], at most MaxMisfires.
+func missedTimes(s Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+	for t := s.Next(from); !t.IsZero() && !t.After(to) && len(times) < MaxMisfires; t = s.Next(t) {
+		times = append(times, t)
+	}
+	return times
+}
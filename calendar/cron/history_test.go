@@ -0,0 +1,99 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistoryRing(t *testing.T) {
+	h := newHistory(3)
+	if len(h.list()) != 0 {
+		t.Fatal("expected empty history")
+	}
+	for i := 1; i <= 5; i++ {
+		h.add(Execution{Duration: time.Duration(i)})
+	}
+	list := h.list()
+	if len(list) != 3 || list[0].Duration != 3 || list[2].Duration != 5 {
+		t.Fatalf("expected the 3 most recent executions, got %v", list)
+	}
+	if newHistory(0) != nil {
+		t.Fatal("expected no history for size 0")
+	}
+	var nilHistory *history
+	nilHistory.add(Execution{})
+}
+
+func TestHooksAndHistory(t *testing.T) {
+	var mu sync.Mutex
+	var events = make(map[string][]Event)
+	record := func(kind string) func(Event) {
+		return func(ev Event) {
+			mu.Lock()
+			events[kind] = append(events[kind], ev)
+			mu.Unlock()
+		}
+	}
+	cron := New()
+	cron.SetHooks(Hooks{
+		OnStart:   record("start"),
+		OnSuccess: record("success"),
+		OnError:   record("error"),
+		OnPanic:   record("panic"),
+	})
+	okID, _ := cron.AddFunc("* * * * * ?", func() {}, WithName("ok"))
+	errID := cron.Schedule(Every(time.Second), ContextFuncJob(func(context.Context) error { return errors.New("oops") }))
+	panicID, _ := cron.AddFunc("* * * * * ?", func() { panic("YOLO") }, WithHistorySize(1))
+	cron.Start()
+	<-time.After(2*OneSecond + 500*time.Millisecond)
+	cron.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events["start"]) == 0 || len(events["success"]) == 0 || len(events["error"]) == 0 || len(events["panic"]) == 0 {
+		t.Fatalf("expected all kinds of events, got %v", events)
+	}
+	for _, ev := range events["success"] {
+		if ev.EntryID != okID || ev.Name != "ok" || ev.Start.IsZero() || ev.Scheduled.IsZero() {
+			t.Errorf("unexpected success event %+v", ev)
+		}
+	}
+
+	okHistory := cron.Entry(okID).History()
+	if len(okHistory) < 2 || okHistory[0].Panicked() || okHistory[0].Err != nil {
+		t.Errorf("unexpected history of the successful entry: %v", okHistory)
+	}
+	if !okHistory[0].Start.Before(okHistory[1].Start) {
+		t.Errorf("expected the oldest first, got %v", okHistory)
+	}
+	if h := cron.Entry(errID).History(); len(h) == 0 || h[0].Err == nil {
+		t.Errorf("expected the error recorded, got %v", h)
+	}
+	h := cron.Entry(panicID).History()
+	if len(h) != 1 || h[0].Panic != "YOLO" || h[0].Stack == "" {
+		t.Errorf("expected the last panic recorded with stack, got %v", h)
+	}
+}
+
+func TestHooksOnMissed(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("job", time.Now().Add(-3*time.Hour-time.Minute))
+	missed := make(chan Event, 1)
+	cron := New()
+	cron.SetStore(store)
+	cron.SetHooks(Hooks{OnMissed: func(ev Event) { missed <- ev }})
+	cron.AddFunc("@every 1h", func() {}, WithName("job"), WithMisfirePolicy(MisfireFireOnce))
+	cron.Start()
+	defer cron.Stop()
+	select {
+	case ev := <-missed:
+		if ev.Name != "job" || ev.Missed != 2 {
+			t.Errorf("expected 2 missed runs after firing once, got %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected OnMissed is called")
+	}
+}
@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/andeya/goutil/calendar"
+)
+
+// JitterSchedule delays each activation of the underlying schedule by a random duration,
+// so that the jobs of the same schedule on many hosts do not stampede the backends.
+type JitterSchedule struct {
+	Schedule Schedule
+	// MaxJitter is the upper bound (exclusive) of the random delay.
+	MaxJitter time.Duration
+}
+
+// Jitter returns a schedule which delays each activation of the schedule by a random duration in [0, max).
+// NOTE:
+//
+//	max should be less than the interval of the schedule, otherwise some activations may be skipped;
+//	The delay is truncated to the second, like the other schedules.
+func Jitter(schedule Schedule, max time.Duration) *JitterSchedule {
+	return &JitterSchedule{Schedule: schedule, MaxJitter: max}
+}
+
+var (
+	jitterRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterRandMu sync.Mutex
+)
+
+// Next returns the next activation time of the underlying schedule plus a random delay.
+func (s *JitterSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t)
+	if next.IsZero() || s.MaxJitter < time.Second {
+		return next
+	}
+	jitterRandMu.Lock()
+	d := time.Duration(jitterRand.Int63n(int64(s.MaxJitter)))
+	jitterRandMu.Unlock()
+	return next.Add(d - d%time.Second)
+}
+
+// LunarNext returns the next lunar time of the underlying schedule, without delay.
+func (s *JitterSchedule) LunarNext(t *calendar.Lunar) *calendar.Lunar {
+	return s.Schedule.LunarNext(t)
+}
@@ -0,0 +1,31 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sched := Jitter(Every(time.Hour), 10*time.Minute)
+	base := Every(time.Hour).Next(from)
+	var delayed bool
+	for i := 0; i < 20; i++ {
+		next := sched.Next(from)
+		d := next.Sub(base)
+		if d < 0 || d >= 10*time.Minute || d%time.Second != 0 {
+			t.Fatalf("unexpected delay %v", d)
+		}
+		if d > 0 {
+			delayed = true
+		}
+	}
+	if !delayed {
+		t.Error("expected some activations are delayed")
+	}
+
+	// The zero time is kept.
+	if next := Jitter(new(ZeroSchedule), time.Minute).Next(from); !next.IsZero() {
+		t.Errorf("expected zero time, got %v", next)
+	}
+}
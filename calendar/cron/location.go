@@ -0,0 +1,34 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/andeya/goutil/calendar"
+)
+
+// LocationSchedule interprets the underlying schedule in the location,
+// instead of the one of the given time, e.g. the one of Cron.
+type LocationSchedule struct {
+	Schedule Schedule
+	Location *time.Location
+}
+
+// InLocation returns a schedule which interprets the schedule in the location.
+func InLocation(schedule Schedule, loc *time.Location) *LocationSchedule {
+	return &LocationSchedule{Schedule: schedule, Location: loc}
+}
+
+// Next returns the next time this schedule is activated, greater than the given time,
+// in the location of the given time.
+func (s *LocationSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t.In(s.Location))
+	if next.IsZero() {
+		return next
+	}
+	return next.In(t.Location())
+}
+
+// LunarNext returns the next lunar time of the underlying schedule.
+func (s *LocationSchedule) LunarNext(t *calendar.Lunar) *calendar.Lunar {
+	return s.Schedule.LunarNext(t)
+}
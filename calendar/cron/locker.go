@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Locker coordinates the replicas of a Cron, so that only one of them runs
+// each scheduled occurrence of a named entry.
+// NOTE:
+//
+//	The entry without name is not coordinated, it runs on every replica;
+//	The schedule should be the same on all the replicas, e.g. without Jitter or random "H" tokens,
+//	so that they agree on the scheduled times.
+type Locker interface {
+	// TryLock tries to claim the occurrence of the named entry scheduled at the time,
+	// returns true if it is claimed by this replica, which should run it.
+	TryLock(name string, scheduled time.Time) (bool, error)
+}
+
+// SetLocker sets the Locker consulted before running a named entry.
+// It should be called before Start.
+func (c *Cron) SetLocker(locker Locker) {
+	c.runningMu.Lock()
+	c.locker = locker
+	c.runningMu.Unlock()
+}
+
+// MemoryLocker is a Locker in memory, which coordinates the Crons in the same process, e.g. for testing.
+type MemoryLocker struct {
+	mu      sync.Mutex
+	claimed map[string]time.Time
+}
+
+// NewMemoryLocker creates a MemoryLocker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{claimed: make(map[string]time.Time)}
+}
+
+// TryLock claims the occurrence if no later or equal one of the entry has been claimed.
+func (l *MemoryLocker) TryLock(name string, scheduled time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.claimed[name]; ok && !last.Before(scheduled) {
+		return false, nil
+	}
+	l.claimed[name] = scheduled
+	return true, nil
+}
+
+// ErrFileLockUnsupported is returned by NewFileLocker on the platforms without flock(2).
+var ErrFileLockUnsupported = errors.New("cron: file lock is not supported on this platform")
+
+// FileLocker is a Locker based on the flock(2) files in a directory,
+// which coordinates the processes on the same host or sharing the directory.
+type FileLocker struct {
+	dir string
+}
+
+// NewFileLocker creates a FileLocker, whose lock files are in dir.
+// It returns ErrFileLockUnsupported on the platforms without flock(2), e.g. windows.
+func NewFileLocker(dir string) (*FileLocker, error) {
+	if !fileLockSupported {
+		return nil, ErrFileLockUnsupported
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileLocker{dir: dir}, nil
+}
+
+// TryLock claims the occurrence if no later or equal one of the entry has been claimed.
+// The last claimed time is recorded in the lock file of the entry, which is exclusively locked meanwhile.
+func (l *FileLocker) TryLock(name string, scheduled time.Time) (bool, error) {
+	f, err := os.OpenFile(filepath.Join(l.dir, url.QueryEscape(name)+".lock"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if err = lockFile(f); err != nil {
+		return false, err
+	}
+	defer unlockFile(f)
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return false, err
+	}
+	if s := strings.TrimSpace(string(b)); s != "" {
+		last, err := strconv.ParseInt(s, 10, 64)
+		if err == nil && last >= scheduled.UnixNano() {
+			return false, nil
+		}
+	}
+	if err = f.Truncate(0); err != nil {
+		return false, err
+	}
+	if _, err = f.WriteAt([]byte(strconv.FormatInt(scheduled.UnixNano(), 10)), 0); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package cron
+
+import "os"
+
+const fileLockSupported = false
+
+func lockFile(f *os.File) error {
+	return ErrFileLockUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return ErrFileLockUnsupported
+}
@@ -0,0 +1,69 @@
+package cron
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLocker(t *testing.T, a, b Locker) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if ok, err := a.TryLock("job", at); !ok || err != nil {
+		t.Fatalf("expected the first claim succeeds, got %v, %v", ok, err)
+	}
+	if ok, err := b.TryLock("job", at); ok || err != nil {
+		t.Fatalf("expected the same occurrence is not claimed twice, got %v, %v", ok, err)
+	}
+	if ok, _ := b.TryLock("other", at); !ok {
+		t.Fatal("expected the other entry is claimed")
+	}
+	if ok, _ := b.TryLock("job", at.Add(time.Second)); !ok {
+		t.Fatal("expected the next occurrence is claimed")
+	}
+	if ok, _ := a.TryLock("job", at); ok {
+		t.Fatal("expected the earlier occurrence is not claimed")
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	l := NewMemoryLocker()
+	testLocker(t, l, l)
+}
+
+func TestFileLocker(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewFileLocker(dir)
+	if !fileLockSupported {
+		if err != ErrFileLockUnsupported {
+			t.Fatalf("expected ErrFileLockUnsupported, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewFileLocker(dir)
+	testLocker(t, a, b)
+}
+
+// Two replicas sharing a locker run each occurrence once.
+func TestCronLocker(t *testing.T) {
+	var calls, unnamed int32
+	locker := NewMemoryLocker()
+	for i := 0; i < 2; i++ {
+		cron := New()
+		cron.SetLocker(locker)
+		cron.AddFunc("* * * * * ?", func() { atomic.AddInt32(&calls, 1) }, WithName("job"))
+		cron.AddFunc("* * * * * ?", func() { atomic.AddInt32(&unnamed, 1) })
+		cron.Start()
+		defer cron.Stop()
+	}
+	<-time.After(2*OneSecond + 500*time.Millisecond)
+	n, u := atomic.LoadInt32(&calls), atomic.LoadInt32(&unnamed)
+	if n == 0 || n > 3 {
+		t.Errorf("called %d times, expected each occurrence runs once", n)
+	}
+	if u < 2*n-1 {
+		t.Errorf("unnamed entry called %d times, expected it runs on every replica", u)
+	}
+}
@@ -0,0 +1,203 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andeya/goutil/calendar"
+)
+
+// LunarSchedule specifies a duty cycle (to the second granularity) on the days
+// of the Chinese lunar calendar, e.g. "every lunar 1st of month at 09:00".
+// The time fields are interpreted in the location of the given time,
+// and the lunar date is the one of the local solar date.
+type LunarSchedule struct {
+	Second, Minute, Hour uint64
+	// Dom is the lunar day of month, in 1-30.
+	Dom uint64
+	// Month is the regular lunar month, in 1-12.
+	Month uint64
+	// LeapMonth is the leap lunar month, in 1-12,
+	// e.g. the bit 4 matches 闰四月, but not 四月.
+	LeapMonth uint64
+}
+
+// The bounds for the lunar fields.
+var (
+	lunarDom    = bounds{1, 30, nil}
+	lunarMonths = bounds{1, 12, nil}
+)
+
+const (
+	// lunarDescriptor is the prefix of a lunar spec.
+	lunarDescriptor = "@lunar "
+	// leapPrefix is the prefix of a lunar month range which matches the leap months.
+	leapPrefix = "leap"
+)
+
+// parseLunar returns a new lunar schedule representing the given spec,
+// which consists of the fields configured by NewParser except the day of week.
+//
+// The month field may contain the leap month ranges, prefixed by "leap":
+//
+//	"8"          the regular 8th month only
+//	"leap4"      the leap 4th month only
+//	"4,leap4"    both the regular and the leap 4th month
+//	"*"          all the months, including the leap months
+func (p Parser) parseLunar(spec string) (Schedule, error) {
+	options := p.options &^ (Dow | DowOptional)
+	fields, err := splitFields(spec, options, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expandHashes(spec, fields, seconds, minutes, hours, lunarDom, lunarMonths); err != nil {
+		return nil, err
+	}
+	var (
+		second     = getFieldOrErr(fields[0], seconds, &err)
+		minute     = getFieldOrErr(fields[1], minutes, &err)
+		hour       = getFieldOrErr(fields[2], hours, &err)
+		dayofmonth = getFieldOrErr(fields[3], lunarDom, &err)
+		month      uint64
+		leapMonth  uint64
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, expr := range strings.FieldsFunc(fields[4], func(r rune) bool { return r == ',' }) {
+		var leap bool
+		if strings.HasPrefix(strings.ToLower(expr), leapPrefix) {
+			leap = true
+			expr = expr[len(leapPrefix):]
+		}
+		bits, err := getRange(expr, lunarMonths)
+		if err != nil {
+			return nil, err
+		}
+		if leap {
+			leapMonth |= bits
+		} else {
+			month |= bits
+			if bits&starBit > 0 {
+				leapMonth |= bits
+			}
+		}
+	}
+	if month|leapMonth == 0 {
+		return nil, fmt.Errorf("Empty lunar month field: %s", spec)
+	}
+	return &LunarSchedule{
+		Second:    second,
+		Minute:    minute,
+		Hour:      hour,
+		Dom:       dayofmonth,
+		Month:     month,
+		LeapMonth: leapMonth,
+	}, nil
+}
+
+// Next returns the next time this schedule is activated, greater than the given
+// time.  If no time can be found to satisfy the schedule before
+// calendar.MaxYear, which is the end of the lunar table, return the zero time.
+func (s *LunarSchedule) Next(t time.Time) time.Time {
+	// Start at the earliest possible time (the upcoming second).
+	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+	loc := t.Location()
+
+	today := calendar.NewSolar(t.Year(), int(t.Month()), t.Day(), 0, 0, 0, 0, calendar.CST)
+	if today == nil {
+		return time.Time{}
+	}
+	l := today.Convert()
+	year, month, leap := l.Year(), l.Month(), l.IsLeapMonth()
+
+	for year < calendar.MaxYear {
+		if s.monthMatches(month, leap) {
+			first := calendar.NewLunar(year, month, 1, 0, 0, 0, 0, leap).Convert()
+			for day := 1; day <= lunarMonthDays(year, month, leap); day++ {
+				if 1<<uint(day)&s.Dom == 0 {
+					continue
+				}
+				date := first.AddDate(0, 0, day-1)
+				from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+				if from.Before(t) {
+					if from.Year() != t.Year() || from.YearDay() != t.YearDay() {
+						continue
+					}
+					from = t
+				}
+				if next, ok := s.nextInDay(from); ok {
+					return next
+				}
+			}
+		}
+		year, month, leap = nextLunarMonth(year, month, leap)
+	}
+	return time.Time{}
+}
+
+// LunarNext returns the next lunar time this schedule is activated, greater than the given
+// lunar time.  If no lunar time can be found to satisfy the schedule, return the zero lunar time.
+func (s *LunarSchedule) LunarNext(t *calendar.Lunar) *calendar.Lunar {
+	next := s.Next(t.GetTime())
+	if next.IsZero() {
+		return calendar.LunarZero
+	}
+	return calendar.NewLunarTime(next)
+}
+
+func (s *LunarSchedule) monthMatches(month int, leap bool) bool {
+	if leap {
+		return 1<<uint(month)&s.LeapMonth > 0
+	}
+	return 1<<uint(month)&s.Month > 0
+}
+
+// nextInDay returns the first activation time on the day of t, not earlier than t.
+func (s *LunarSchedule) nextInDay(t time.Time) (time.Time, bool) {
+	for h := t.Hour(); h < 24; h++ {
+		if 1<<uint(h)&s.Hour == 0 {
+			continue
+		}
+		var m int
+		if h == t.Hour() {
+			m = t.Minute()
+		}
+		for ; m < 60; m++ {
+			if 1<<uint(m)&s.Minute == 0 {
+				continue
+			}
+			var sec int
+			if h == t.Hour() && m == t.Minute() {
+				sec = t.Second()
+			}
+			for ; sec < 60; sec++ {
+				if 1<<uint(sec)&s.Second > 0 {
+					return time.Date(t.Year(), t.Month(), t.Day(), h, m, sec, 0, t.Location()), true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// lunarMonthDays returns the days of the regular or leap lunar month.
+func lunarMonthDays(year, month int, leap bool) int {
+	if leap {
+		return calendar.LeapDays(year)
+	}
+	return calendar.LunarMonthDays(year, month)
+}
+
+// nextLunarMonth returns the lunar month after the given one,
+// the leap month follows the regular month of the same number.
+func nextLunarMonth(year, month int, leap bool) (int, int, bool) {
+	if !leap && calendar.LeapMonth(year) == month {
+		return year, month, true
+	}
+	if month == 12 {
+		return year + 1, 1, false
+	}
+	return year, month + 1, false
+}
@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLunarNext(t *testing.T) {
+	tests := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Mid-Autumn Festival, lunar 8/15.
+		{"2024-01-01 00:00:00", "@lunar 0 0 9 15 8", "2024-09-17 09:00:00"},
+		{"2024-09-17 09:00:00", "@lunar 0 0 9 15 8", "2025-10-06 09:00:00"},
+		{"2024-09-17 08:59:59", "@lunar 0 0 9 15 8", "2024-09-17 09:00:00"},
+
+		// Every lunar 1st of month, including the leap month 闰二月 of 2023.
+		{"2023-02-21 00:00:00", "@lunar 0 0 9 1 *", "2023-03-22 09:00:00"},
+		{"2023-03-22 09:00:00", "@lunar 0 0 9 1 *", "2023-04-20 09:00:00"},
+
+		// The regular 2nd month only, or the leap one only.
+		{"2023-01-01 00:00:00", "@lunar 0 0 0 1 2", "2023-02-20 00:00:00"},
+		{"2023-02-21 00:00:00", "@lunar 0 0 0 1 2", "2024-03-10 00:00:00"},
+		{"2023-01-01 00:00:00", "@lunar 0 0 0 1 leap2", "2023-03-22 00:00:00"},
+		{"2023-01-01 00:00:00", "@lunar 0 0 0 1 2,leap2", "2023-02-20 00:00:00"},
+
+		// Time fields within the day.
+		{"2024-09-17 09:30:00", "@lunar 0 */20 9-10 15 8", "2024-09-17 09:40:00"},
+		{"2024-09-17 10:40:00", "@lunar 0 */20 9-10 15 8", "2025-10-06 09:00:00"},
+
+		// Day 30 is skipped in the months of 29 days.
+		{"2024-02-10 00:00:00", "@lunar 0 0 0 30 1", "2025-02-27 00:00:00"},
+
+		// No match before the end of the lunar table.
+		{"2024-01-01 00:00:00", "@lunar 0 0 9 1 leap4", ""},
+		{"2049-12-01 00:00:00", "@lunar 0 0 9 15 8", ""},
+	}
+
+	for _, c := range tests {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		from, _ := time.ParseInLocation("2006-01-02 15:04:05", c.time, time.UTC)
+		expected, _ := time.ParseInLocation("2006-01-02 15:04:05", c.expected, time.UTC)
+		actual := sched.Next(from)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestLunarParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"@lunar 0 0 9 31 8",
+		"@lunar 0 0 9 15 13",
+		"@lunar 0 0 9 15 leapx",
+		"@lunar 0 0 9 15 8 *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+	sched, err := ParseStandard("@lunar 30 8 1 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sched.(*LunarSchedule); s.Second != 1 || s.Minute != 1<<30 || s.Hour != 1<<8 {
+		t.Errorf("unexpected standard lunar schedule: %+v", s)
+	}
+}
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"strconv"
 	"strings"
@@ -23,6 +24,7 @@ const (
 	Dow                                 // Day of week field, default *
 	DowOptional                         // Optional day of week field, default *
 	Descriptor                          // Allow descriptors such as @monthly, @weekly, etc.
+	Extended                            // Allow the Quartz extensions L, W and # in the day fields
 )
 
 var places = []ParseOption{
@@ -47,6 +49,7 @@ var defaults = []string{
 type Parser struct {
 	options   ParseOption
 	optionals int
+	hashKey   string
 }
 
 // Creates a custom Parser with custom options.
@@ -69,28 +72,117 @@ func NewParser(options ParseOption) Parser {
 		options |= Dow
 		optionals++
 	}
-	return Parser{options, optionals}
+	return Parser{options: options, optionals: optionals}
+}
+
+// WithHashKey returns a copy of the parser, which resolves the "H" tokens by the hash of key
+// together with the spec, e.g. the host name, so that the same spec spreads over the hosts
+// but is stable on each host.
+// Without the hash key, the "H" tokens are resolved by the hash of the spec only.
+func (p Parser) WithHashKey(key string) Parser {
+	p.hashKey = key
+	return p
 }
 
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
 // It accepts crontab specs and features configured by NewParser.
+//
+// The spec may be prefixed by "CRON_TZ=" or "TZ=" with a location name,
+// then the schedule is interpreted in that location instead of the one of Cron, e.g.
+//
+//	CRON_TZ=Asia/Shanghai 0 0 9 * * *
+//
+// The "H" tokens are resolved by the hash of the spec and the hash key, see WithHashKey,
+// so the same spec always resolves the same schedule.
 func (p Parser) Parse(spec string) (Schedule, error) {
+	var loc *time.Location
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("Missing spec after time zone: %s", spec)
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		var err error
+		loc, err = time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("Provided bad location %s: %v", name, err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+	schedule, err := p.parse(spec)
+	if err != nil || loc == nil {
+		return schedule, err
+	}
+	return InLocation(schedule, loc), nil
+}
+
+func (p Parser) parse(spec string) (Schedule, error) {
 	if len(spec) == 0 {
 		return nil, fmt.Errorf("Empty spec string")
 	}
 	if spec[0] == '@' && p.options&Descriptor > 0 {
+		if strings.HasPrefix(spec, lunarDescriptor) {
+			return p.parseLunar(spec[len(lunarDescriptor):])
+		}
 		return parseDescriptor(spec)
 	}
 
+	fields, err := splitFields(spec, p.options, p.optionals)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expandHashes(spec, fields, seconds, minutes, hours, dom, months, dow); err != nil {
+		return nil, err
+	}
+	var ext *specExt
+	if p.options&Extended > 0 {
+		ext = new(specExt)
+		if fields[3], err = parseDomExt(fields[3], ext); err != nil {
+			return nil, err
+		}
+		if fields[5], err = parseDowExt(fields[5], ext); err != nil {
+			return nil, err
+		}
+		if !ext.hasDom() && !ext.hasDow() {
+			ext = nil
+		}
+	}
+
+	var (
+		second     = getFieldOrErr(fields[0], seconds, &err)
+		minute     = getFieldOrErr(fields[1], minutes, &err)
+		hour       = getFieldOrErr(fields[2], hours, &err)
+		dayofmonth = getFieldOrErr(fields[3], dom, &err)
+		month      = getFieldOrErr(fields[4], months, &err)
+		dayofweek  = getFieldOrErr(fields[5], dow, &err)
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpecSchedule{
+		Second: second,
+		Minute: minute,
+		Hour:   hour,
+		Dom:    dayofmonth,
+		Month:  month,
+		Dow:    dayofweek,
+		ext:    ext,
+	}, nil
+}
+
+// splitFields splits the spec on whitespace, validates the number of fields
+// and fills in the missing ones with defaults.
+func splitFields(spec string, options ParseOption, optionals int) ([]string, error) {
 	// Figure out how many fields we need
 	max := 0
 	for _, place := range places {
-		if p.options&place > 0 {
+		if options&place > 0 {
 			max++
 		}
 	}
-	min := max - p.optionals
+	min := max - optionals
 
 	// Split fields on whitespace
 	fields := strings.Fields(spec)
@@ -104,38 +196,113 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 	}
 
 	// Fill in missing fields
-	fields = expandFields(fields, p.options)
+	return expandFields(fields, options), nil
+}
 
-	var err error
-	field := func(field string, r bounds) uint64 {
-		if err != nil {
-			return 0
+// expandHashes rewrites the "H" tokens of the fields of the spec into the numeric ranges,
+// the bounds are the ones of the fields in order.
+//
+// The forms in the Jenkins style are accepted:
+//
+//	H              a value in the bounds, e.g. "H" in the minutes field is like "17"
+//	H/step         every step starting at a value less than step, e.g. "H/15" is like "7-59/15"
+//	H(a-b)         a value within a-b, e.g. "H(0-5)" in the hours field is like "3"
+//	H(a-b)/step    every step within a-b starting at a value less than a+step
+//
+// Without the explicit range, "H" in the day of month field (the 4th) resolves within 1-28,
+// so that the day exists in every month.
+func (p Parser) expandHashes(spec string, fields []string, rs ...bounds) error {
+	for i, field := range fields {
+		if !strings.Contains(field, "H") {
+			continue
 		}
-		var bits uint64
-		bits, err = getField(field, r)
-		return bits
+		items := strings.Split(field, ",")
+		for j, item := range items {
+			if !strings.HasPrefix(item, "H") {
+				continue
+			}
+			expr, err := p.expandHash(item, rs[i], p.hash(spec, i), i == 3)
+			if err != nil {
+				return err
+			}
+			items[j] = expr
+		}
+		fields[i] = strings.Join(items, ",")
 	}
+	return nil
+}
 
-	var (
-		second     = field(fields[0], seconds)
-		minute     = field(fields[1], minutes)
-		hour       = field(fields[2], hours)
-		dayofmonth = field(fields[3], dom)
-		month      = field(fields[4], months)
-		dayofweek  = field(fields[5], dow)
-	)
-	if err != nil {
-		return nil, err
+// maxHashDom is the max day of month resolved by "H" without the explicit range.
+const maxHashDom = 28
+
+// expandHash rewrites the "H" expression into a numeric range by the hash value h.
+// If dom is true, the field is the day of month.
+func (p Parser) expandHash(expr string, r bounds, h uint, dom bool) (string, error) {
+	min, max := r.min, r.max
+	rest := expr[1:]
+	if strings.HasPrefix(rest, "(") {
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return "", fmt.Errorf("Unclosed hash range: %s", expr)
+		}
+		lowAndHigh := strings.Split(rest[1:end], "-")
+		if len(lowAndHigh) != 2 {
+			return "", fmt.Errorf("Hash range should be a-b: %s", expr)
+		}
+		var err error
+		if min, err = mustParseInt(lowAndHigh[0]); err != nil {
+			return "", err
+		}
+		if max, err = mustParseInt(lowAndHigh[1]); err != nil {
+			return "", err
+		}
+		if min < r.min || max > r.max || min > max {
+			return "", fmt.Errorf("Hash range (%d-%d) out of bounds (%d-%d): %s", min, max, r.min, r.max, expr)
+		}
+		rest = rest[end+1:]
+	} else if dom && max > maxHashDom {
+		max = maxHashDom
 	}
+	switch {
+	case rest == "":
+		return strconv.Itoa(int(min + h%(max-min+1))), nil
+	case rest[0] == '/':
+		step, err := mustParseInt(rest[1:])
+		if err != nil {
+			return "", err
+		}
+		if step == 0 {
+			return "", fmt.Errorf("Step of range should be a positive number: %s", expr)
+		}
+		if step > max-min+1 {
+			step = max - min + 1
+		}
+		return fmt.Sprintf("%d-%d/%d", min+h%step, max, step), nil
+	default:
+		return "", fmt.Errorf("Invalid hash expression: %s", expr)
+	}
+}
 
-	return &SpecSchedule{
-		Second: second,
-		Minute: minute,
-		Hour:   hour,
-		Dom:    dayofmonth,
-		Month:  month,
-		Dow:    dayofweek,
-	}, nil
+// hash returns the hash value of the i-th field of the spec, mixed with the hash key,
+// so that the different specs spread, and the same spec is stable across the restarts.
+func (p Parser) hash(spec string, i int) uint {
+	h := fnv.New32a()
+	h.Write([]byte(p.hashKey))
+	h.Write([]byte{0})
+	h.Write([]byte(spec))
+	h.Write([]byte{0, byte(i)})
+	return uint(h.Sum32())
+}
+
+// getFieldOrErr is like getField, but does nothing if *err is not nil,
+// and stores the error into *err, so that the fields can be parsed in sequence.
+func getFieldOrErr(field string, r bounds, err *error) uint64 {
+	if *err != nil {
+		return 0
+	}
+	var bits uint64
+	bits, *err = getField(field, r)
+	return bits
 }
 
 func expandFields(fields []string, options ParseOption) []string {
@@ -167,6 +334,9 @@ var standardParser = NewParser(
 // It accepts
 //   - Standard crontab specs, e.g. "* * * * ?"
 //   - Descriptors, e.g. "@midnight", "@every 1h30m"
+//   - Lunar specs, e.g. "@lunar 0 9 15 8", "@lunar 0 0 1 *"
+//   - Location prefixes, e.g. "CRON_TZ=Asia/Shanghai 0 9 * * *"
+//   - Hash "H" tokens, e.g. "H/15 * * * *"
 func ParseStandard(standardSpec string) (Schedule, error) {
 	return standardParser.Parse(standardSpec)
 }
@@ -181,6 +351,9 @@ var defaultParser = NewParser(
 // It accepts
 //   - Full crontab specs, e.g. "* * * * * ?"
 //   - Descriptors, e.g. "@midnight", "@every 1h30m"
+//   - Lunar specs, e.g. "@lunar 0 0 9 15 8", "@lunar 0 0 0 1 *"
+//   - Location prefixes, e.g. "CRON_TZ=Asia/Shanghai 0 0 9 * * *"
+//   - Hash "H" tokens, e.g. "H H/15 * * * *"
 func Parse(spec string) (Schedule, error) {
 	return defaultParser.Parse(spec)
 }
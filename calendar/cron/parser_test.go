@@ -1,7 +1,9 @@
 package cron
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -202,8 +204,15 @@ func TestStandardSpecSchedule(t *testing.T) {
 		err      string
 	}{
 		{
-			expr:     "5 * * * *",
-			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow)},
+			expr: "5 * * * *",
+			expected: &SpecSchedule{
+				Second: 1 << seconds.min,
+				Minute: 1 << 5,
+				Hour:   all(hours),
+				Dom:    all(dom),
+				Month:  all(months),
+				Dow:    all(dow),
+			},
 		},
 		{
 			expr:     "@every 5m",
@@ -232,3 +241,100 @@ func TestStandardSpecSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestParseHash(t *testing.T) {
+	p := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor).WithHashKey("host-1")
+	a, err := p.Parse("H H/15 H(9-17) * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := p.Parse("H H/15 H(9-17) * * *")
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the same key resolves the same schedule, got %b and %b", a, b)
+	}
+	s := a.(*SpecSchedule)
+	if n := bitCount(s.Second); n != 1 {
+		t.Errorf("expected H resolves a single second, got %d", n)
+	}
+	if n := bitCount(s.Minute); n != 4 || s.Minute&getBits(0, 14, 1) == 0 {
+		t.Errorf("expected H/15 resolves 4 minutes starting before 15, got %b", s.Minute)
+	}
+	if n := bitCount(s.Hour); n != 1 || s.Hour&getBits(9, 17, 1) == 0 {
+		t.Errorf("expected H(9-17) resolves an hour within 9-17, got %b", s.Hour)
+	}
+
+	// The seconds of different keys are not always the same.
+	var seconds = make(map[uint64]bool)
+	for i := 0; i < 10; i++ {
+		sched, _ := p.WithHashKey(strings.Repeat("x", i+1)).Parse("H * * * * *")
+		seconds[sched.(*SpecSchedule).Second] = true
+	}
+	if len(seconds) < 2 {
+		t.Errorf("expected different keys spread, got %v", seconds)
+	}
+
+	// Without the key, the same spec is stable, and the different specs spread.
+	a, _ = Parse("H H * * * *")
+	b, _ = Parse("H H * * * *")
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the same spec resolves the same schedule, got %b and %b", a, b)
+	}
+	var minutes = make(map[uint64]bool)
+	for i := 0; i < 10; i++ {
+		sched, _ := Parse(fmt.Sprintf("%d H * * * *", i))
+		minutes[sched.(*SpecSchedule).Minute] = true
+	}
+	if len(minutes) < 2 {
+		t.Errorf("expected different specs spread, got %v", minutes)
+	}
+
+	// H in the day of month resolves within 1-28 unless the range is explicit.
+	for i := 0; i < 200; i++ {
+		sched, err := p.WithHashKey(strconv.Itoa(i)).Parse("0 0 0 H,H/10 * *")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dom := sched.(*SpecSchedule).Dom &^ starBit; dom&^getBits(1, maxHashDom, 1) != 0 {
+			t.Fatalf("expected H resolves the days within 1-28, got %b", dom)
+		}
+	}
+	sched, err := p.Parse("0 0 0 H(31-31) * *")
+	if err != nil || sched.(*SpecSchedule).Dom&^starBit != 1<<31 {
+		t.Errorf("expected H(31-31) resolves the 31st, got %v, %v", sched, err)
+	}
+
+	for _, spec := range []string{"H(5) * * * * *", "H(0-60) * * * * *", "H/0 * * * * *", "Hx * * * * *", "H(1-2 * * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
+
+func bitCount(bits uint64) int {
+	bits &^= starBit
+	var n int
+	for ; bits > 0; bits &= bits - 1 {
+		n++
+	}
+	return n
+}
+
+func TestParseLocation(t *testing.T) {
+	sched, err := Parse("CRON_TZ=Asia/Shanghai 0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 09:00 in Shanghai is 01:00 in UTC.
+	if next := sched.Next(from); !next.Equal(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)) || next.Location() != time.UTC {
+		t.Errorf("unexpected next time %v", next)
+	}
+	if _, err = ParseStandard("TZ=Asia/Shanghai 0 9 * * *"); err != nil {
+		t.Error(err)
+	}
+	for _, spec := range []string{"TZ=Nowhere/Nothing 0 0 9 * * *", "CRON_TZ=UTC"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
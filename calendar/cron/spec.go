@@ -10,6 +10,9 @@ import (
 // traditional crontab specification. It is computed initially and stored as bit sets.
 type SpecSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// ext holds the Quartz extensions, which are not supported by LunarNext.
+	ext *specExt
 }
 
 // bounds provides a range of acceptable values (plus a map of name to value).
@@ -242,8 +245,8 @@ WRAP:
 // restrictions are satisfied by the given time.
 func dayMatches(s *SpecSchedule, t time.Time) bool {
 	var (
-		domMatch bool = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch bool = 1<<uint(t.Day())&s.Dom > 0 || s.ext.domMatches(t)
+		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0 || s.ext.dowMatches(t)
 	)
 	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
 		return domMatch && dowMatch
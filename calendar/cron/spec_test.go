@@ -179,6 +179,63 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestNextExtended(t *testing.T) {
+	p := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Descriptor | Extended)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Last day of month
+		{"Mon Jul 9 14:45 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+		{"Wed Feb 1 00:00 2012", "0 0 0 L * ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 L-2 * ?", "Sun Jul 29 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nearest weekday
+		{"Mon Jul 9 14:45 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Sat Sep 1 00:00 2012", "0 0 0 15W * ?", "Fri Sep 14 00:00 2012"},
+		{"Thu Aug 2 00:00 2012", "0 0 0 1W * ?", "Mon Sep 3 00:00 2012"},
+		{"Sat Sep 1 00:00 2012", "0 0 0 LW * ?", "Fri Sep 28 00:00 2012"},
+		{"Sat Sep 1 00:00 2012", "0 0 0 31W * ?", "Wed Oct 31 00:00 2012"},
+
+		// Last and nth day of week
+		{"Mon Jul 9 14:45 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 ? * FRI#3", "Fri Jul 20 00:00 2012"},
+		{"Sat Jul 21 00:00 2012", "0 0 0 ? * 5#3", "Fri Aug 17 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 ? * 1#5", "Mon Oct 29 00:00 2012"},
+		{"Mon Jul 9 14:45 2012", "0 0 0 ? * L", "Sat Jul 14 00:00 2012"},
+
+		// Both restricted days are ORed
+		{"Mon Jul 9 14:45 2012", "0 0 0 L * 5#3", "Fri Jul 20 00:00 2012"},
+	}
+
+	for _, c := range runs {
+		sched, err := p.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": expected %v, actual %v", c.time, c.spec, expected, actual)
+		}
+	}
+
+	// The extensions are errors without the Extended option.
+	for _, spec := range []string{"0 0 0 L * ?", "0 0 0 15W * ?", "0 0 0 ? * 5#3"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+	for _, spec := range []string{"0 0 0 L-31 * ?", "0 0 0 32W * ?", "0 0 0 ? * 7L", "0 0 0 ? * 5#6", "0 0 0 ? * 5#x"} {
+		if _, err := p.Parse(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
+
 func TestErrors(t *testing.T) {
 	invalidSpecs := []string{
 		"xyz",
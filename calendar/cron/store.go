@@ -0,0 +1,178 @@
+package cron
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store persists the last run times of the named entries,
+// so that the runs missed while the process is down can be caught up on Start.
+type Store interface {
+	// Get returns the last run time of the named entry,
+	// or the zero time if it has never been run.
+	Get(name string) (time.Time, error)
+	// Set sets the last run time of the named entry.
+	Set(name string, prev time.Time) error
+}
+
+// MisfirePolicy decides how to handle the runs missed while the process is down.
+type MisfirePolicy int
+
+const (
+	// MisfireSkip skips the missed runs, which is the default.
+	MisfireSkip MisfirePolicy = iota
+	// MisfireFireOnce runs the job once if any run is missed.
+	MisfireFireOnce
+	// MisfireFireAll runs the job for every missed run in sequence, up to MaxMisfires times.
+	MisfireFireAll
+)
+
+// MaxMisfires is the maximum number of the missed runs counted at a time,
+// which are fired by MisfireFireAll or reported to Hooks.OnMissed.
+var MaxMisfires = 100
+
+// catchUp loads the last run time of the entry from the store,
+// and runs the job for the missed runs according to its misfire policy.
+func (c *Cron) catchUp(e *Entry, now time.Time) {
+	if c.store == nil || e.Name == "" {
+		return
+	}
+	prev, err := c.store.Get(e.Name)
+	if err != nil {
+		c.logf("cron: failed to load the last run of %s: %v", e.Name, err)
+		return
+	}
+	if prev.IsZero() {
+		return
+	}
+	e.Prev = prev
+	if e.Paused {
+		return
+	}
+	missed := missedTimes(e.Schedule, prev, now)
+	if len(missed) == 0 {
+		return
+	}
+	var fire []time.Time
+	switch e.MisfirePolicy {
+	case MisfireFireOnce:
+		fire = missed[:1]
+		e.Prev = now
+	case MisfireFireAll:
+		fire = missed
+		e.Prev = missed[len(missed)-1]
+	}
+	c.reportMissed(e, missed[len(fire):])
+	if len(fire) == 0 {
+		return
+	}
+	go func() {
+		for _, t := range fire {
+			c.runScheduled(e, t)
+		}
+	}()
+	c.saveLastRun(e)
+}
+
+// saveLastRun saves the last run time of the entry to the store.
+func (c *Cron) saveLastRun(e *Entry) {
+	if c.store == nil || e.Name == "" {
+		return
+	}
+	if err := c.store.Set(e.Name, e.Prev); err != nil {
+		c.logf("cron: failed to save the last run of %s: %v", e.Name, err)
+	}
+}
+
+// MemoryStore is a Store in memory, e.g. for testing.
+type MemoryStore struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{last: make(map[string]time.Time)}
+}
+
+// Get returns the last run time of the named entry.
+func (s *MemoryStore) Get(name string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last[name], nil
+}
+
+// Set sets the last run time of the named entry.
+func (s *MemoryStore) Set(name string, prev time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last[name] = prev
+	return nil
+}
+
+// FileStore is a Store persisting the last run times in a JSON file.
+// It's safe for concurrent use by multiple goroutines, but not by multiple processes.
+type FileStore struct {
+	filename string
+	mu       sync.Mutex
+	last     map[string]time.Time
+}
+
+// NewFileStore creates a FileStore, loading the last run times from the file if it exists.
+func NewFileStore(filename string) (*FileStore, error) {
+	s := &FileStore{
+		filename: filename,
+		last:     make(map[string]time.Time),
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err = json.Unmarshal(b, &s.last); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Get returns the last run time of the named entry.
+func (s *FileStore) Get(name string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last[name], nil
+}
+
+// Set sets the last run time of the named entry, and writes the file.
+// The file is replaced atomically, so that it is never left half-written.
+func (s *FileStore) Set(name string, prev time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last[name] = prev
+	b, err := json.MarshalIndent(s.last, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
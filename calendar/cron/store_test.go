@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cron.json")
+	s, err := NewFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev, _ := s.Get("job"); !prev.IsZero() {
+		t.Fatalf("expected zero time, got %v", prev)
+	}
+	last := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err = s.Set("job", last); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev, _ := s.Get("job"); !prev.Equal(last) {
+		t.Fatalf("expected %v, got %v", last, prev)
+	}
+}
+
+func TestMisfirePolicy(t *testing.T) {
+	tests := []struct {
+		policy   MisfirePolicy
+		expected int32
+	}{
+		{MisfireSkip, 0},
+		{MisfireFireOnce, 1},
+		{MisfireFireAll, 3},
+	}
+	for _, c := range tests {
+		store := NewMemoryStore()
+		prev := time.Now().Add(-3*time.Hour - time.Minute)
+		store.Set("job", prev)
+
+		var calls int32
+		cron := New()
+		cron.SetStore(store)
+		id, _ := cron.AddFunc("@every 1h", func() { atomic.AddInt32(&calls, 1) },
+			WithName("job"), WithMisfirePolicy(c.policy))
+		cron.Start()
+		time.Sleep(100 * time.Millisecond)
+		entry := cron.Entry(id)
+		cron.Stop()
+
+		if n := atomic.LoadInt32(&calls); n != c.expected {
+			t.Errorf("policy %d: called %d times, expected %d", c.policy, n, c.expected)
+		}
+		if !entry.Prev.After(prev) == (c.expected > 0) {
+			t.Errorf("policy %d: unexpected prev %v", c.policy, entry.Prev)
+		}
+		if saved, _ := store.Get("job"); !saved.Equal(entry.Prev) {
+			t.Errorf("policy %d: saved %v, expected %v", c.policy, saved, entry.Prev)
+		}
+	}
+}
+
+// The run is saved to the store.
+func TestStoreSavesRuns(t *testing.T) {
+	store := NewMemoryStore()
+	cron := New()
+	cron.SetStore(store)
+	cron.AddFunc("* * * * * ?", func() {}, WithName("job"))
+	cron.AddFunc("* * * * * ?", func() {})
+	cron.Start()
+	defer cron.Stop()
+	<-time.After(2 * OneSecond)
+	if prev, _ := store.Get("job"); prev.IsZero() {
+		t.Error("expected the run is saved")
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.last) != 1 {
+		t.Errorf("expected only the named entry is saved, got %v", store.last)
+	}
+}
@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ContextJob is a Job that accepts a context and reports an error.
+// The built-in JobWrappers pass the context through and use the error,
+// e.g. the context of Timeout is canceled when the run times out,
+// and Retry runs the job again when it returns an error.
+type ContextJob interface {
+	Job
+	RunContext(context.Context) error
+}
+
+// ContextFuncJob is a wrapper that turns a func(context.Context) error into a cron.ContextJob.
+type ContextFuncJob func(context.Context) error
+
+// Run calls f with the background context.
+func (f ContextFuncJob) Run() { f(context.Background()) }
+
+// RunContext calls f(ctx).
+func (f ContextFuncJob) RunContext(ctx context.Context) error { return f(ctx) }
+
+// runJob runs the job with the context if it is a ContextJob, otherwise runs it directly.
+func runJob(ctx context.Context, j Job) error {
+	if cj, ok := j.(ContextJob); ok {
+		return cj.RunContext(ctx)
+	}
+	j.Run()
+	return nil
+}
+
+// JobWrapper decorates the given Job with some behavior.
+type JobWrapper func(Job) Job
+
+// Chain returns a JobWrapper that decorates the given Job with all the wrappers,
+// the first wrapper is the outermost one, i.e.
+//
+//	Chain(m1, m2, m3)(job) = m1(m2(m3(job)))
+func Chain(wrappers ...JobWrapper) JobWrapper {
+	return func(j Job) Job {
+		for i := len(wrappers) - 1; i >= 0; i-- {
+			if wrappers[i] != nil {
+				j = wrappers[i](j)
+			}
+		}
+		return j
+	}
+}
+
+// SkipIfStillRunning skips an invocation of the Job if a previous invocation is still running.
+func SkipIfStillRunning() JobWrapper {
+	return func(j Job) Job {
+		var ch = make(chan struct{}, 1)
+		ch <- struct{}{}
+		return ContextFuncJob(func(ctx context.Context) error {
+			select {
+			case v := <-ch:
+				defer func() { ch <- v }()
+				return runJob(ctx, j)
+			default:
+				return nil
+			}
+		})
+	}
+}
+
+// DelayIfStillRunning serializes the invocations of the Job,
+// delaying subsequent runs until the previous one is complete.
+func DelayIfStillRunning() JobWrapper {
+	return func(j Job) Job {
+		var mu sync.Mutex
+		return ContextFuncJob(func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return runJob(ctx, j)
+		})
+	}
+}
+
+// Timeout bounds each run of the Job to d, by canceling its context after d.
+// NOTE:
+//
+//	Only the ContextJob observes the cancellation, the other Job runs to completion;
+//	If the context is done, Timeout returns the context error instead of nil.
+func Timeout(d time.Duration) JobWrapper {
+	return func(j Job) Job {
+		return ContextFuncJob(func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			err := runJob(ctx, j)
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		})
+	}
+}
+
+// Retry runs the Job again when it returns an error, up to maxRetries times.
+// The delay before the n-th retry is backoff*2^(n-1), no more than maxBackoff if it is given.
+// NOTE:
+//
+//	Only the ContextJob reports an error, so the other Job is never retried;
+//	The waiting is interrupted when the context is done;
+//	Returns the last error if all the retries fail.
+func Retry(maxRetries int, backoff time.Duration, maxBackoff ...time.Duration) JobWrapper {
+	var max time.Duration
+	if len(maxBackoff) > 0 {
+		max = maxBackoff[0]
+	}
+	return func(j Job) Job {
+		return ContextFuncJob(func(ctx context.Context) error {
+			delay := backoff
+			for n := 0; ; n++ {
+				err := runJob(ctx, j)
+				if err == nil || n >= maxRetries {
+					return err
+				}
+				if max > 0 && delay > max {
+					delay = max
+				}
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return fmt.Errorf("%v (retry aborted: %v)", err, ctx.Err())
+				}
+				delay *= 2
+			}
+		})
+	}
+}
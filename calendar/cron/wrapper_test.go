@@ -0,0 +1,156 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) JobWrapper {
+		return func(j Job) Job {
+			return FuncJob(func() {
+				order = append(order, name)
+				j.Run()
+			})
+		}
+	}
+	Chain(mark("m1"), nil, mark("m2"))(FuncJob(func() { order = append(order, "job") })).Run()
+	if len(order) != 3 || order[0] != "m1" || order[1] != "m2" || order[2] != "job" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestSkipIfStillRunning(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	j := SkipIfStillRunning()(FuncJob(func() {
+		atomic.AddInt32(&calls, 1)
+		<-release
+	}))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		j.Run()
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	j.Run() // skipped
+	close(release)
+	wg.Wait()
+	j.Run()
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("called %d times, expected 2", n)
+	}
+}
+
+func TestDelayIfStillRunning(t *testing.T) {
+	var running, overlapped int32
+	j := DelayIfStillRunning()(FuncJob(func() {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.Run()
+		}()
+	}
+	wg.Wait()
+	if overlapped != 0 {
+		t.Fatal("expected runs do not overlap")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	j := Timeout(10 * time.Millisecond)(ContextFuncJob(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}))
+	err := runJob(context.Background(), j)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	j = Timeout(time.Second)(FuncJob(func() {}))
+	if err = runJob(context.Background(), j); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	j := Retry(3, time.Millisecond, 2*time.Millisecond)(ContextFuncJob(func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	}))
+	if err := runJob(context.Background(), j); err != nil || calls != 3 {
+		t.Fatalf("expected success at the 3rd call, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	j = Retry(2, time.Millisecond)(ContextFuncJob(func(ctx context.Context) error {
+		calls++
+		return errors.New("fail")
+	}))
+	if err := runJob(context.Background(), j); err == nil || calls != 3 {
+		t.Fatalf("expected failure after 3 calls, got %v after %d calls", err, calls)
+	}
+
+	// Retry inside Timeout stops waiting when the run times out.
+	calls = 0
+	j = Chain(Timeout(20*time.Millisecond), Retry(10, time.Hour))(ContextFuncJob(func(ctx context.Context) error {
+		calls++
+		return errors.New("fail")
+	}))
+	start := time.Now()
+	if err := runJob(context.Background(), j); err == nil || calls != 1 || time.Since(start) > time.Second {
+		t.Fatalf("expected aborted retry, got %v after %d calls", err, calls)
+	}
+}
+
+// Global and per-entry wrappers decorate the job run by the Cron.
+func TestCronWrappers(t *testing.T) {
+	var calls int32
+	var wrapped int32
+	count := func(j Job) Job {
+		return FuncJob(func() {
+			atomic.AddInt32(&wrapped, 1)
+			j.Run()
+		})
+	}
+	cron := New()
+	cron.Use(count)
+	release := make(chan struct{})
+	id, _ := cron.AddFunc("* * * * * ?", func() {
+		atomic.AddInt32(&calls, 1)
+		<-release
+	}, SkipIfStillRunning())
+	cron.Start()
+	defer cron.Stop()
+	defer close(release)
+
+	<-time.After(3 * OneSecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("called %d times, expected 1", n)
+	}
+	if n := atomic.LoadInt32(&wrapped); n < 2 {
+		t.Errorf("global wrapper called %d times, expected at least 2", n)
+	}
+	if _, ok := cron.Entry(id).Job.(FuncJob); !ok {
+		t.Error("expected entry keeps the original job")
+	}
+}
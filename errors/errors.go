@@ -3,6 +3,7 @@ package errors
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -29,6 +30,38 @@ func Errorf(format string, a ...interface{}) error {
 	return New(fmt.Sprintf(format, a...))
 }
 
+// Is reports whether any error in err's tree matches target, the same as the standard errors.Is.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's tree that matches target, the same as the standard errors.As.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, the same as the standard errors.Unwrap.
+// NOTE:
+//  Returns nil for the merged errors, use Errors instead
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Errors returns the individual errors merged by Merge or Append.
+// NOTE:
+//  If err is not merged, returns []error{err};
+//  If err is nil, returns nil
+func Errors(err error) []error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *multiError:
+		return append([]error(nil), e.errs...)
+	default:
+		return []error{err}
+	}
+}
+
 // Merge merges multiple errors.
 func Merge(errs ...error) error {
 	return Append(nil, errs...)
@@ -94,3 +127,8 @@ func (m *multiError) Error() string {
 	m.text = goutil.BytesToString(bText)
 	return m.text
 }
+
+// Unwrap returns the merged errors, for errors.Is and errors.As.
+func (m *multiError) Unwrap() []error {
+	return m.errs
+}
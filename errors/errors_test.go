@@ -2,6 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"testing"
 )
 
@@ -22,3 +25,28 @@ func TestErrors(t *testing.T) {
 	err = Append(err, errs...)
 	t.Log(err)
 }
+
+func TestMergeIs(t *testing.T) {
+	var errNotFound = New("not found")
+	err := Merge(errNotFound, nil, fmt.Errorf("read: %w", io.EOF))
+	if !Is(err, io.EOF) || !errors.Is(err, errNotFound) {
+		t.Error("expected the merged error matches its members")
+	}
+	if Is(err, io.ErrUnexpectedEOF) {
+		t.Error("unexpected match")
+	}
+	var pathErr *os.PathError
+	err = Append(err, &os.PathError{Op: "open", Path: "/x", Err: os.ErrNotExist})
+	if !As(err, &pathErr) || pathErr.Path != "/x" {
+		t.Error("expected errors.As finds the member")
+	}
+	if errs := Errors(err); len(errs) != 3 || errs[0] != errNotFound {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs := Errors(io.EOF); len(errs) != 1 || errs[0] != io.EOF {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if Errors(nil) != nil {
+		t.Error("expected nil errors")
+	}
+}
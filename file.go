@@ -468,7 +468,14 @@ func CopyFile(src, dst string) error {
 }
 
 // CopyDir copies a whole directory recursively.
-func CopyDir(src string, dst string) error {
+// NOTE:
+//
+//	If ignore is not empty, skips the files matched by any of them, whose paths are relative to src.
+func CopyDir(src string, dst string, ignore ...*IgnoreMatcher) error {
+	return copyDir(src, dst, "", ignore)
+}
+
+func copyDir(src string, dst string, rel string, ignore []*IgnoreMatcher) error {
 	var err error
 	var fds []os.FileInfo
 	var srcinfo os.FileInfo
@@ -487,9 +494,13 @@ func CopyDir(src string, dst string) error {
 	for _, fd := range fds {
 		srcfp := path.Join(src, fd.Name())
 		dstfp := path.Join(dst, fd.Name())
+		relfp := path.Join(rel, fd.Name())
 
+		if matchAny(ignore, relfp, fd.IsDir()) {
+			continue
+		}
 		if fd.IsDir() {
-			if err = CopyDir(srcfp, dstfp); err != nil {
+			if err = copyDir(srcfp, dstfp, relfp, ignore); err != nil {
 				return err
 			}
 		} else {
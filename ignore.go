@@ -0,0 +1,233 @@
+package goutil
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreMatcher matches paths against .gitignore-style patterns.
+// Syntax:
+//
+//	# comment           blank lines and lines starting with # are ignored
+//	*.log               matches the name at any level
+//	!keep.log           negation, re-includes a previously ignored path
+//	build/              a trailing slash matches directories only
+//	/bin                a leading or middle slash anchors to the base directory
+//	**/tmp, a/**, a/**/b  ** matches zero or more directories
+//
+// NOTE:
+//
+//	Like git, a path cannot be re-included if one of its parent directories is ignored.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	base    string // the slash separated base directory, empty or ending with /
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// NewIgnoreMatcher creates an ignore matcher with the patterns relative to the root directory.
+func NewIgnoreMatcher(patterns ...string) *IgnoreMatcher {
+	m := new(IgnoreMatcher)
+	m.Add(patterns...)
+	return m
+}
+
+// LoadIgnoreMatcher creates an ignore matcher from the .gitignore-style files,
+// whose patterns are relative to the root directory.
+func LoadIgnoreMatcher(filenames ...string) (*IgnoreMatcher, error) {
+	m := new(IgnoreMatcher)
+	for _, filename := range filenames {
+		if err := m.AddFile(filename); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add adds the patterns relative to the root directory.
+func (m *IgnoreMatcher) Add(patterns ...string) {
+	m.add("", patterns)
+}
+
+// AddFile adds the patterns of the .gitignore-style file.
+// NOTE:
+//
+//	baseDir is the directory the patterns are relative to, such as "sub" for "sub/.gitignore";
+//	If baseDir is empty, default is the root directory.
+func (m *IgnoreMatcher) AddFile(filename string, baseDir ...string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	var base string
+	if len(baseDir) > 0 {
+		base = strings.Trim(filepath.ToSlash(filepath.Clean(baseDir[0])), "/")
+		if base == "." {
+			base = ""
+		}
+	}
+	m.add(base, lines)
+	return nil
+}
+
+func (m *IgnoreMatcher) add(base string, patterns []string) {
+	if base != "" {
+		base += "/"
+	}
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, "\r")
+		if strings.HasSuffix(p, `\ `) {
+			p = strings.TrimRight(p[:len(p)-2], " ") + `\ `
+		} else {
+			p = strings.TrimRight(p, " ")
+		}
+		if p == "" || p[0] == '#' {
+			continue
+		}
+		var ip = ignorePattern{base: base}
+		if p[0] == '!' {
+			ip.negate = true
+			p = p[1:]
+		} else if p[0] == '\\' && len(p) > 1 && (p[1] == '!' || p[1] == '#') {
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			ip.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if p == "" {
+			continue
+		}
+		anchored := strings.Contains(p, "/")
+		p = strings.TrimPrefix(p, "/")
+		expr := globToRegexp(p)
+		if anchored {
+			expr = "^" + expr + "$"
+		} else {
+			expr = "^(?:.*/)?" + expr + "$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		ip.re = re
+		m.patterns = append(m.patterns, ip)
+	}
+}
+
+// globToRegexp converts the glob pattern to a regular expression without anchors.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' && (i == 0 || glob[i-1] == '/') {
+				switch {
+				case i+2 == len(glob):
+					// trailing "/**" matches everything inside
+					b.WriteString(".*")
+					i++
+					continue
+				case glob[i+2] == '/':
+					// leading "**/" or middle "/**/" matches zero or more directories
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(glob[i+1:], ']')
+			if j < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += j + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				c = glob[i]
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether the path, relative to the root directory, is ignored.
+// If m is nil, returns false.
+func (m *IgnoreMatcher) Match(name string, isDir bool) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+	name = strings.Trim(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "" || name == "." {
+		return false
+	}
+	// A path is ignored if any of its parent directories is ignored.
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' && m.match(name[:i], true) {
+			return true
+		}
+	}
+	return m.match(name, isDir)
+}
+
+// matchAny reports whether the path is ignored by any of the matchers.
+func matchAny(matchers []*IgnoreMatcher, name string, isDir bool) bool {
+	for _, m := range matchers {
+		if m.Match(name, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *IgnoreMatcher) match(name string, isDir bool) bool {
+	var ignored bool
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		rel := name
+		if p.base != "" {
+			if !strings.HasPrefix(name, p.base) {
+				continue
+			}
+			rel = name[len(p.base):]
+		}
+		if p.re.MatchString(rel) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,153 @@
+package goutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := NewIgnoreMatcher(
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/bin",
+		"**/tmp",
+		"docs/**/*.md",
+		"vendor/**",
+	)
+	cases := []struct {
+		name   string
+		isDir  bool
+		expect bool
+	}{
+		{"a.log", false, true},
+		{"sub/a.log", false, true},
+		{"keep.log", false, false},
+		{"sub/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"sub/build/x.go", false, true},
+		{"bin", true, true},
+		{"sub/bin", true, false},
+		{"tmp", true, true},
+		{"a/b/tmp/c.go", false, true},
+		{"docs/x.md", false, true},
+		{"docs/a/b/x.md", false, true},
+		{"docs/a/b/x.txt", false, false},
+		{"vendor/a/b.go", false, true},
+		{"main.go", false, false},
+		{"# comment", false, false},
+	}
+	for _, c := range cases {
+		if got := m.Match(c.name, c.isDir); got != c.expect {
+			t.Errorf("Match(%q, %v): expect %v, got %v", c.name, c.isDir, c.expect, got)
+		}
+	}
+	var nilMatcher *IgnoreMatcher
+	if nilMatcher.Match("a.log", false) {
+		t.Error("nil matcher should match nothing")
+	}
+}
+
+func TestIgnoreMatcherAddFile(t *testing.T) {
+	dir := t.TempDir()
+	gitignore := filepath.Join(dir, ".gitignore")
+	if err := WriteFile(gitignore, []byte("*.o\n!main.o\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadIgnoreMatcher(gitignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.AddFile(gitignore, "sub"); err != nil {
+		t.Fatal(err)
+	}
+	m.Add("/sub/*.txt")
+	if !m.Match("a/b.o", false) || m.Match("main.o", false) || !m.Match("sub/a.txt", false) || m.Match("a.txt", false) {
+		t.Fatal("unexpected matching result")
+	}
+}
+
+func TestCopyDirAndArchiveWithIgnore(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"a.go", "a.log", "keep.log", "build/x", "sub/b.go", "tmp/c.go"} {
+		if err := WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewIgnoreMatcher("*.log", "!keep.log", "build/")
+	expect := []string{"a.go", "keep.log", "sub/b.go"}
+	ignored := []string{"a.log", "build"}
+
+	// All the matchers are applied.
+	dst := t.TempDir()
+	if err := CopyDir(src, dst, m, NewIgnoreMatcher("tmp/")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range expect {
+		if !FileExists(filepath.Join(dst, name)) {
+			t.Errorf("CopyDir: %s should be copied", name)
+		}
+	}
+	ignored = append(ignored, "tmp")
+	for _, name := range ignored {
+		if FileExists(filepath.Join(dst, name)) {
+			t.Errorf("CopyDir: %s should be ignored", name)
+		}
+	}
+
+	// The matcher may be mixed with the ignored names.
+	for _, format := range []ArchiveFormat{ArchiveTar, ArchiveTarGz, ArchiveZip} {
+		var buf = bytes.NewBuffer(nil)
+		if err := format.(MatcherArchiver).ArchiveWithMatcher(src, buf, true, t.Logf, m, "tmp"); err != nil {
+			t.Fatalf("%s: %v", format.Name(), err)
+		}
+		dst = t.TempDir()
+		if err := ExtractFrom(buf, dst, nil); err != nil {
+			t.Fatalf("%s: %v", format.Name(), err)
+		}
+		base := filepath.Base(src)
+		for _, name := range expect {
+			if _, err := os.Stat(filepath.Join(dst, base, name)); err != nil {
+				t.Errorf("%s: %s should be packaged", format.Name(), name)
+			}
+		}
+		for _, name := range ignored {
+			if FileExists(filepath.Join(dst, base, name)) {
+				t.Errorf("%s: %s should be ignored", format.Name(), name)
+			}
+		}
+	}
+
+	dstFile := filepath.Join(t.TempDir(), "x.tar.gz")
+	if err := TarGzWithMatcher(src, dstFile, false, nil, m, "tmp"); err != nil {
+		t.Fatal(err)
+	}
+	dst = t.TempDir()
+	if err := Extract(dstFile, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(filepath.Join(dst, "a.go")) || FileExists(filepath.Join(dst, "a.log")) || FileExists(filepath.Join(dst, "tmp")) {
+		t.Error("TarGzWithMatcher: unexpected packaged files")
+	}
+	dstFile = filepath.Join(t.TempDir(), "x.zip")
+	if err := ArchiveWithMatcher(src, dstFile, false, nil, m); err != nil {
+		t.Fatal(err)
+	}
+	dst = t.TempDir()
+	if err := Extract(dstFile, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(filepath.Join(dst, "tmp", "c.go")) || FileExists(filepath.Join(dst, "a.log")) {
+		t.Error("ArchiveWithMatcher: unexpected packaged files")
+	}
+
+	// The ignored names can be spread from a slice.
+	elems := []string{"tmp", "build"}
+	if err := TarGzTo(src, bytes.NewBuffer(nil), true, nil, elems...); err != nil {
+		t.Fatal(err)
+	}
+}
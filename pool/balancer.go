@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+type (
+	// Balancer selects a worker when the workshop hires.
+	// It is called while the workshop is locked, so it need not be safe for concurrent use,
+	// but it must not call the methods of the workshop.
+	Balancer interface {
+		// Select returns the index of the selected worker in workers.
+		// The workers are in the order of creation and len(workers)>0.
+		// If returns an index out of range, the worker with the fewest tasks is selected.
+		Select(workers []WorkerLoad) int
+	}
+	// BalancerFunc is an adapter to use an ordinary function as a Balancer.
+	BalancerFunc func(workers []WorkerLoad) int
+	// WorkerLoad is the load information of a worker.
+	WorkerLoad struct {
+		Worker  Worker
+		Load    int32         // The number of tasks in progress
+		Done    uint64        // The total number of tasks completed
+		Latency time.Duration // The moving average of Callback cost, zero if unknown
+	}
+)
+
+// Select calls f(workers).
+func (f BalancerFunc) Select(workers []WorkerLoad) int {
+	return f(workers)
+}
+
+// LeastLoadBalancer returns a balancer that selects the worker with the fewest tasks.
+func LeastLoadBalancer() Balancer {
+	return BalancerFunc(func(workers []WorkerLoad) int {
+		var idx int
+		for i, w := range workers {
+			if w.Load < workers[idx].Load {
+				idx = i
+			}
+		}
+		return idx
+	})
+}
+
+// RoundRobinBalancer returns a balancer that selects the workers in turn.
+func RoundRobinBalancer() Balancer {
+	var next int
+	return BalancerFunc(func(workers []WorkerLoad) int {
+		if next >= len(workers) {
+			next = 0
+		}
+		idx := next
+		next++
+		return idx
+	})
+}
+
+// WeightedBalancer returns a smooth weighted round-robin balancer,
+// weight returns the weight of the worker, and the worker with weight<=0 is not selected
+// unless all the weights are not positive.
+func WeightedBalancer(weight func(Worker) int) Balancer {
+	current := make(map[Worker]int)
+	return BalancerFunc(func(workers []WorkerLoad) int {
+		var total int
+		var idx = -1
+		for i, w := range workers {
+			wt := weight(w.Worker)
+			if wt <= 0 {
+				continue
+			}
+			total += wt
+			current[w.Worker] += wt
+			if idx < 0 || current[w.Worker] > current[workers[idx].Worker] {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return -1
+		}
+		current[workers[idx].Worker] -= total
+		// Forget the fired workers.
+		if len(current) > 2*len(workers) {
+			alive := make(map[Worker]int, len(workers))
+			for _, w := range workers {
+				if cw, ok := current[w.Worker]; ok {
+					alive[w.Worker] = cw
+				}
+			}
+			current = alive
+		}
+		return idx
+	})
+}
+
+// P2CBalancer returns a power-of-two-choices balancer,
+// which randomly picks two workers and selects the one with fewer tasks,
+// or lower latency if the tasks are equal.
+func P2CBalancer() Balancer {
+	return BalancerFunc(func(workers []WorkerLoad) int {
+		n := len(workers)
+		if n == 1 {
+			return 0
+		}
+		a := rand.Intn(n)
+		b := rand.Intn(n - 1)
+		if b >= a {
+			b++
+		}
+		wa, wb := workers[a], workers[b]
+		if wb.Load < wa.Load || (wb.Load == wa.Load && wb.Latency < wa.Latency) {
+			return b
+		}
+		return a
+	})
+}
+
+// LeastLatencyBalancer returns a latency-aware balancer,
+// which selects the worker with the lowest expected cost (Load+1)*Latency.
+// The workers with unknown latency are preferred, so that they can be measured.
+func LeastLatencyBalancer() Balancer {
+	return BalancerFunc(func(workers []WorkerLoad) int {
+		var idx int
+		var min = math.MaxFloat64
+		for i, w := range workers {
+			cost := float64(w.Load+1) * float64(w.Latency)
+			if cost < min {
+				idx, min = i, cost
+			}
+		}
+		return idx
+	})
+}
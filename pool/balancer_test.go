@@ -0,0 +1,65 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkshopBalancer(t *testing.T) {
+	var seq int
+	newWorker := func() (Worker, error) {
+		seq++
+		return &testWorker{seq}, nil
+	}
+	w := NewWorkshop(3, time.Minute, newWorker, WithBalancer(RoundRobinBalancer()))
+	defer w.Close()
+	var hired []Worker
+	for i := 0; i < 6; i++ {
+		worker, err := w.Hire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hired = append(hired, worker)
+	}
+	for i := 3; i < 6; i++ {
+		if hired[i] != hired[i-3] {
+			t.Fatalf("expect round-robin, got worker %d at %d", hired[i].(*testWorker).int, i)
+		}
+	}
+	for _, worker := range hired {
+		w.Fire(worker)
+	}
+	if stats := w.Stats(); stats.Worker != 3 || stats.Doing != 0 || stats.Done != 6 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestWeightedBalancer(t *testing.T) {
+	a, b := &testWorker{1}, &testWorker{2}
+	workers := []WorkerLoad{{Worker: a}, {Worker: b}}
+	balancer := WeightedBalancer(func(w Worker) int { return w.(*testWorker).int })
+	var counts [2]int
+	for i := 0; i < 30; i++ {
+		counts[balancer.Select(workers)]++
+	}
+	if counts[0] != 10 || counts[1] != 20 {
+		t.Fatalf("unexpected distribution: %v", counts)
+	}
+}
+
+func TestLatencyBalancer(t *testing.T) {
+	workers := []WorkerLoad{
+		{Load: 1, Latency: time.Millisecond * 10},
+		{Load: 3, Latency: time.Millisecond},
+		{Load: 0, Latency: time.Millisecond * 30},
+	}
+	if i := LeastLatencyBalancer().Select(workers); i != 1 {
+		t.Fatalf("expect 1, got %d", i)
+	}
+	p2c := P2CBalancer()
+	for i := 0; i < 10; i++ {
+		if i := p2c.Select(workers[1:]); i != 1 {
+			t.Fatalf("expect 1, got %d", i)
+		}
+	}
+}
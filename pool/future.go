@@ -0,0 +1,188 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/andeya/goutil/errors"
+	"github.com/andeya/goutil/status"
+)
+
+// PanicError is the error of a task recovered from panic.
+type PanicError struct {
+	Recovered interface{}
+	Stack     status.StackTrace
+}
+
+// Error returns the text of the recovered value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v", e.Recovered)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Recovered.(error)
+	return err
+}
+
+// Future is the pending result of a task submitted to GoPool.
+type Future[T any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	value  T
+	err    error
+}
+
+// Submit submits the task to the GoPool, and returns its pending result.
+// NOTE:
+//
+//	If there are no idle goroutines and no room in the wait queue, Submit blocks until the task is accepted or ctx is done;
+//	If ctx is done before the task starts, the task is skipped and the result error is ctx.Err();
+//	If the task is dropped from the wait queue or expires in it, the result error is ErrQueueFull or ErrQueueExpired;
+//	The panic of the task is recovered as the result error *PanicError, with the panic stack.
+func Submit[T any](ctx context.Context, gp *GoPool, fn func(context.Context) (T, error)) *Future[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future[T]{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	task := func() {
+		defer cancel()
+		if err := ctx.Err(); err != nil {
+			f.finish(f.value, err)
+			return
+		}
+		value, err := callTask(ctx, fn)
+		f.finish(value, err)
+	}
+	reject := func(err error) {
+		cancel()
+		f.finish(f.value, err)
+	}
+	if err := gp.mustGo(task, reject, ctx); err != nil {
+		reject(err)
+	}
+	return f
+}
+
+func (f *Future[T]) finish(value T, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+}
+
+// Done returns a channel that is closed when the task is completed or skipped.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel cancels the context of the task.
+// If the task has not started, it will be skipped.
+func (f *Future[T]) Cancel() {
+	f.cancel()
+}
+
+// Wait waits for the task to complete, and returns its error.
+func (f *Future[T]) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// Get waits for the task to complete, and returns its result.
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// GetContext waits for the task to complete or ctx to be done, and returns its result.
+func (f *Future[T]) GetContext(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Group is a collection of tasks running on the GoPool, like errgroup,
+// but the concurrency is bounded by the maxGoroutinesAmount of the GoPool.
+type Group struct {
+	gp     *GoPool
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	lock   sync.Mutex
+	errs   []error
+}
+
+// NewGroup creates a task group running on the GoPool,
+// and returns a derived context which is canceled the first time a task returns a non-nil error,
+// or the first time Wait returns.
+func NewGroup(ctx context.Context, gp *GoPool) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{gp: gp, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Go runs the task on the GoPool.
+// If there are no idle goroutines, Go blocks until the task is accepted or the group context is done.
+// NOTE:
+//
+//	The panic of the task is recovered as its error *PanicError, with the panic stack;
+//	If the task can not be started because the GoPool is stopped or the group context is done, the error is recorded too;
+//	If the task is dropped from the wait queue or expires in it, its error is ErrQueueFull or ErrQueueExpired;
+//	After the group context is done, the tasks that have not started are skipped.
+func (g *Group) Go(fn func(context.Context) error) {
+	g.wg.Add(1)
+	task := func() {
+		defer g.wg.Done()
+		if g.ctx.Err() != nil {
+			return
+		}
+		_, err := callTask(g.ctx, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, fn(ctx)
+		})
+		if err != nil {
+			g.fail(err)
+		}
+	}
+	reject := func(err error) {
+		defer g.wg.Done()
+		g.fail(err)
+	}
+	if err := g.gp.mustGo(task, reject, g.ctx); err != nil {
+		reject(err)
+	}
+}
+
+// fail records the error of a task, and cancels the group context.
+func (g *Group) fail(err error) {
+	g.lock.Lock()
+	g.errs = append(g.errs, err)
+	g.lock.Unlock()
+	g.cancel()
+}
+
+// callTask runs fn, and recovers its panic as *PanicError with the panic stack, the same as GoPool.runTask.
+func callTask[T any](ctx context.Context, fn func(context.Context) (T, error)) (value T, err error) {
+	panicked := true
+	defer func() {
+		if panicked {
+			err = &PanicError{Recovered: recover(), Stack: status.PanicStackTrace()}
+		}
+	}()
+	value, err = fn(ctx)
+	panicked = false
+	return value, err
+}
+
+// Wait blocks until all the tasks have completed,
+// then returns all their errors merged by errors.Merge, or nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return errors.Merge(g.errs...)
+}
@@ -0,0 +1,97 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmit(t *testing.T) {
+	gp := NewGoPool(1, 0)
+	defer gp.Stop()
+	f := Submit(context.Background(), gp, func(context.Context) (int, error) {
+		return 1 + 2, nil
+	})
+	if v, err := f.Get(); v != 3 || err != nil {
+		t.Fatalf("expect 3, got %d, %v", v, err)
+	}
+
+	f = Submit(context.Background(), gp, func(context.Context) (int, error) {
+		panic("oops")
+	})
+	if err := f.Wait(); err == nil || err.Error() != "oops" {
+		t.Fatalf("expect panic error, got %v", err)
+	} else if pe, ok := err.(*PanicError); !ok || pe.Recovered != "oops" || len(pe.Stack) == 0 {
+		t.Fatalf("expect *PanicError with the panic stack, got %#v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f = Submit(ctx, gp, func(context.Context) (int, error) {
+		t.Fatal("canceled task should not run")
+		return 0, nil
+	})
+	if err := f.Wait(); err != context.Canceled {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	gp := NewGoPool(2, 0)
+	defer gp.Stop()
+	g, _ := NewGroup(context.Background(), gp)
+	var sum int32
+	for i := 1; i <= 10; i++ {
+		i := int32(i)
+		g.Go(func(context.Context) error {
+			atomic.AddInt32(&sum, i)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil || sum != 55 {
+		t.Fatalf("expect 55, got %d, %v", sum, err)
+	}
+
+	g, ctx := NewGroup(context.Background(), gp)
+	g.Go(func(context.Context) error {
+		return errors.New("failed")
+	})
+	g.Go(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("expect the group canceled")
+		}
+	})
+	if err := g.Wait(); err == nil || err.Error() != "MultiError:\n1. failed\n" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("expect the group context canceled")
+	}
+
+	g, _ = NewGroup(context.Background(), gp)
+	g.Go(func(context.Context) error {
+		panic("oops")
+	})
+	err := g.Wait()
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Recovered != "oops" || len(pe.Stack) == 0 {
+		t.Fatalf("expect *PanicError with the panic stack, got %v", err)
+	}
+
+	// The task can not be started because the group context is done.
+	parent, cancel := context.WithCancel(context.Background())
+	cancel()
+	g, _ = NewGroup(parent, gp)
+	g.Go(func(context.Context) error {
+		t.Error("the task should not run")
+		return nil
+	})
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+}
@@ -3,11 +3,14 @@ package pool
 import (
 	"context"
 	"errors"
+	"log"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andeya/goutil/coarsetime"
+	"github.com/andeya/goutil/status"
 )
 
 type (
@@ -26,8 +29,24 @@ type (
 		ready             []*goroutineChan
 		stopCh            chan struct{}
 		goroutineChanPool sync.Pool
+		freeCh            chan struct{} // closed when a goroutine or a queue slot is freed, nil if no waiters
+
+		queue        *taskQueue // nil means no wait queue
+		panicHandler func(recovered interface{}, stack status.StackTrace)
+		beforeTask   func() interface{}
+		afterTask    func(state interface{}, cost time.Duration, panicked bool)
+
+		queued  int32
+		running int32
+		done    uint64
+		panics  uint64
+		dropped uint64
+		expired uint64
 	}
 
+	// GoPoolOption is an option of NewGoPool.
+	GoPoolOption func(*GoPool)
+
 	goroutineChan struct {
 		lastUseTime time.Time
 		ch          chan func()
@@ -41,10 +60,52 @@ const (
 	DefaultMaxGoroutineIdleDuration = 10 * time.Second
 )
 
+// WithPanicHandler sets the handler of the panic in a task,
+// which receives the recovered value and the panic stack trace.
+// If handler is nil, the panic is logged by the standard logger.
+func WithPanicHandler(handler func(recovered interface{}, stack status.StackTrace)) GoPoolOption {
+	return func(gp *GoPool) {
+		gp.panicHandler = handler
+	}
+}
+
+// WithQueue enables the bounded wait queue for the tasks when there are no idle goroutines.
+// NOTE:
+//
+//	size is the max number of the waiting tasks, if size<=0, the queue is disabled;
+//	maxWait is the max time a task may wait, the expired tasks are not run, if maxWait<=0, no limit;
+//	The Submit futures and the Group tasks dropped or expired fail with ErrQueueFull or ErrQueueExpired;
+//	policy decides what to do when the queue is full.
+func WithQueue(size int, maxWait time.Duration, policy RejectPolicy) GoPoolOption {
+	return func(gp *GoPool) {
+		if size <= 0 {
+			gp.queue = nil
+			return
+		}
+		gp.queue = &taskQueue{
+			size:    size,
+			maxWait: maxWait,
+			policy:  policy,
+		}
+	}
+}
+
+// WithTaskHooks sets the hooks called before and after each task, in the goroutine of the task,
+// e.g. for tracing and timing.
+// The return value of before is passed to after as state;
+// cost is the execution time of the task, and panicked reports whether the task panicked.
+// Either of them can be nil.
+func WithTaskHooks(before func() interface{}, after func(state interface{}, cost time.Duration, panicked bool)) GoPoolOption {
+	return func(gp *GoPool) {
+		gp.beforeTask = before
+		gp.afterTask = after
+	}
+}
+
 // NewGoPool creates a new *GoPool.
 // If maxGoroutinesAmount<=0, will use default value.
 // If maxGoroutineIdleDuration<=0, will use default value.
-func NewGoPool(maxGoroutinesAmount int, maxGoroutineIdleDuration time.Duration) *GoPool {
+func NewGoPool(maxGoroutinesAmount int, maxGoroutineIdleDuration time.Duration, opts ...GoPoolOption) *GoPool {
 	gp := new(GoPool)
 	if maxGoroutinesAmount <= 0 {
 		gp.maxGoroutinesAmount = DefaultMaxGoroutinesAmount
@@ -56,6 +117,9 @@ func NewGoPool(maxGoroutinesAmount int, maxGoroutineIdleDuration time.Duration)
 	} else {
 		gp.maxGoroutineIdleDuration = maxGoroutineIdleDuration
 	}
+	for _, opt := range opts {
+		opt(gp)
+	}
 	gp.start()
 	return gp
 }
@@ -70,6 +134,37 @@ func (gp *GoPool) MaxGoroutineIdle() time.Duration {
 	return gp.maxGoroutineIdleDuration
 }
 
+// GoPoolStats go pool stats
+type GoPoolStats struct {
+	Goroutines    int32  // The current total number of goroutines
+	Idle          int32  // The current number of idle goroutines
+	MaxGoroutines int32  // The maximum amount of goroutines
+	Queued        int32  // The number of accepted tasks waiting to run
+	Running       int32  // The number of tasks in progress
+	Done          uint64 // The total number of tasks completed, including the panicked
+	Panics        uint64 // The total number of panicked tasks
+	Dropped       uint64 // The total number of queued tasks dropped by RejectDropOldest
+	Expired       uint64 // The total number of queued tasks not run due to the max wait time
+}
+
+// Stats returns the current go pool stats.
+func (gp *GoPool) Stats() GoPoolStats {
+	gp.lock.Lock()
+	stats := GoPoolStats{
+		Goroutines:    int32(gp.goroutinesCount),
+		Idle:          int32(len(gp.ready)),
+		MaxGoroutines: int32(gp.maxGoroutinesAmount),
+	}
+	gp.lock.Unlock()
+	stats.Queued = atomic.LoadInt32(&gp.queued)
+	stats.Running = atomic.LoadInt32(&gp.running)
+	stats.Done = atomic.LoadUint64(&gp.done)
+	stats.Panics = atomic.LoadUint64(&gp.panics)
+	stats.Dropped = atomic.LoadUint64(&gp.dropped)
+	stats.Expired = atomic.LoadUint64(&gp.expired)
+	return stats
+}
+
 // start starts GoPool.
 func (gp *GoPool) start() {
 	if gp.stopCh != nil {
@@ -111,18 +206,46 @@ func (gp *GoPool) Stop() {
 	}
 	gp.ready = ready[:0]
 	gp.mustStop = true
+	gp.notifyFreeLocked()
 	gp.lock.Unlock()
 }
 
 var ErrLack = errors.New("lack of goroutines, because exceeded maxGoroutinesAmount limit")
 
 // Go executes the function via a goroutine.
-// If returns non-nil, the function cannot be executed because exceeded maxGoroutinesAmount limit.
+// If returns non-nil, the function cannot be executed because exceeded maxGoroutinesAmount limit,
+// or the wait queue is full.
 func (gp *GoPool) Go(fn func()) error {
-	ch := gp.getCh()
+	return gp.GoPriority(PriorityNormal, fn)
+}
+
+// GoPriority executes the function via a goroutine.
+// If there are no idle goroutines and the wait queue is enabled,
+// the function waits in the queue, and the higher priority ones run first.
+// If returns non-nil, the function cannot be executed because exceeded maxGoroutinesAmount limit,
+// or the wait queue is full.
+func (gp *GoPool) GoPriority(priority Priority, fn func()) error {
+	return gp.goPriority(priority, fn, nil)
+}
+
+// goPriority is the same as GoPriority, and if fn is dropped from the wait queue by RejectDropOldest
+// or expires in it, reject is called with ErrQueueFull or ErrQueueExpired.
+func (gp *GoPool) goPriority(priority Priority, fn func(), reject func(error)) error {
+	ch, err := gp.getCh(fn, reject, priority)
+	switch err {
+	case nil:
+	case errCallerRuns:
+		atomic.AddInt32(&gp.queued, 1)
+		gp.runTask(fn)
+		return nil
+	default:
+		return err
+	}
 	if ch == nil {
-		return ErrLack
+		// waiting in the queue
+		return nil
 	}
+	atomic.AddInt32(&gp.queued, 1)
 	ch.ch <- fn
 	return nil
 }
@@ -138,26 +261,54 @@ func (gp *GoPool) TryGo(fn func()) {
 // MustGo always try to use goroutine callbacks
 // until execution is complete or the context is canceled.
 func (gp *GoPool) MustGo(fn func(), ctx ...context.Context) error {
-	if len(ctx) == 0 {
-		for gp.Go(fn) != nil {
-			runtime.Gosched()
-		}
-		return nil
+	return gp.mustGo(fn, nil, ctx...)
+}
+
+// mustGo is the same as MustGo, and reject is called as goPriority does.
+// It blocks without spinning until a goroutine or a queue slot is freed.
+func (gp *GoPool) mustGo(fn func(), reject func(error), ctx ...context.Context) error {
+	var done <-chan struct{} // nil blocks forever
+	if len(ctx) > 0 {
+		done = ctx[0].Done()
 	}
-	c := ctx[0]
 	for {
 		select {
-		case <-c.Done():
-			return c.Err()
+		case <-done:
+			return ctx[0].Err()
 		default:
-			if gp.Go(fn) == nil {
-				return nil
-			}
-			runtime.Gosched()
+		}
+		// Get the channel before trying, so that no notification is missed.
+		freed := gp.freed()
+		if gp.goPriority(PriorityNormal, fn, reject) == nil {
+			return nil
+		}
+		select {
+		case <-freed:
+		case <-done:
+			return ctx[0].Err()
 		}
 	}
 }
 
+// freed returns a channel which is closed when a goroutine or a queue slot is freed.
+func (gp *GoPool) freed() <-chan struct{} {
+	gp.lock.Lock()
+	if gp.freeCh == nil {
+		gp.freeCh = make(chan struct{})
+	}
+	ch := gp.freeCh
+	gp.lock.Unlock()
+	return ch
+}
+
+// notifyFreeLocked wakes up the callers blocked in mustGo, it must be called with gp.lock held.
+func (gp *GoPool) notifyFreeLocked() {
+	if gp.freeCh != nil {
+		close(gp.freeCh)
+		gp.freeCh = nil
+	}
+}
+
 func (gp *GoPool) clean(scratch *[]*goroutineChan) {
 	maxGoroutineIdleDuration := gp.maxGoroutineIdleDuration
 
@@ -207,7 +358,9 @@ var goroutineChanCap = func() int {
 	return 1
 }()
 
-func (gp *GoPool) getCh() *goroutineChan {
+// getCh returns an idle goroutine,
+// or pushes fn into the wait queue and returns nil if there are no idle goroutines.
+func (gp *GoPool) getCh(fn func(), reject func(error), priority Priority) (*goroutineChan, error) {
 	var ch *goroutineChan
 	createGoroutine := false
 
@@ -218,6 +371,10 @@ func (gp *GoPool) getCh() *goroutineChan {
 		if gp.goroutinesCount < gp.maxGoroutinesAmount {
 			createGoroutine = true
 			gp.goroutinesCount++
+		} else if gp.queue != nil {
+			err := gp.queue.pushLocked(gp, fn, reject, priority)
+			gp.lock.Unlock()
+			return nil, err
 		}
 	} else {
 		ch = ready[n]
@@ -228,7 +385,7 @@ func (gp *GoPool) getCh() *goroutineChan {
 
 	if ch == nil {
 		if !createGoroutine {
-			return nil
+			return nil, ErrLack
 		}
 		vch := gp.goroutineChanPool.Get()
 		if vch == nil {
@@ -242,19 +399,27 @@ func (gp *GoPool) getCh() *goroutineChan {
 			gp.goroutineChanPool.Put(vch)
 		}()
 	}
-	return ch
+	return ch, nil
 }
 
-func (gp *GoPool) release(ch *goroutineChan) bool {
+// release returns the next queued task if exists,
+// otherwise puts the goroutine back to the ready list.
+// If ok is false, the goroutine should stop.
+func (gp *GoPool) release(ch *goroutineChan) (next func(), ok bool) {
 	ch.lastUseTime = coarsetime.FloorTimeNow()
 	gp.lock.Lock()
+	defer gp.lock.Unlock()
+	gp.notifyFreeLocked()
+	if gp.queue != nil {
+		if next = gp.queue.popLocked(gp); next != nil {
+			return next, true
+		}
+	}
 	if gp.mustStop {
-		gp.lock.Unlock()
-		return false
+		return nil, false
 	}
 	gp.ready = append(gp.ready, ch)
-	gp.lock.Unlock()
-	return true
+	return nil, true
 }
 
 func (gp *GoPool) goroutineFunc(ch *goroutineChan) {
@@ -262,13 +427,60 @@ func (gp *GoPool) goroutineFunc(ch *goroutineChan) {
 		if fn == nil {
 			break
 		}
-		fn()
-		if !gp.release(ch) {
+		if !gp.serve(ch, fn) {
 			break
 		}
 	}
 
 	gp.lock.Lock()
 	gp.goroutinesCount--
+	gp.notifyFreeLocked()
 	gp.lock.Unlock()
 }
+
+// serve runs the task and then the queued tasks,
+// returns false if the goroutine should stop.
+func (gp *GoPool) serve(ch *goroutineChan, fn func()) bool {
+	for {
+		gp.runTask(fn)
+		next, ok := gp.release(ch)
+		if next == nil {
+			return ok
+		}
+		fn = next
+	}
+}
+
+// runTask runs the task with the hooks, and recovers its panic.
+func (gp *GoPool) runTask(fn func()) {
+	atomic.AddInt32(&gp.queued, -1)
+	atomic.AddInt32(&gp.running, 1)
+	var state interface{}
+	if gp.beforeTask != nil {
+		state = gp.beforeTask()
+	}
+	start := time.Now()
+	panicked := true
+	defer func() {
+		if panicked {
+			p := recover()
+			atomic.AddUint64(&gp.panics, 1)
+			gp.handlePanic(p, status.PanicStackTrace())
+		}
+		atomic.AddInt32(&gp.running, -1)
+		atomic.AddUint64(&gp.done, 1)
+		if gp.afterTask != nil {
+			gp.afterTask(state, time.Since(start), panicked)
+		}
+	}()
+	fn()
+	panicked = false
+}
+
+func (gp *GoPool) handlePanic(recovered interface{}, stack status.StackTrace) {
+	if gp.panicHandler != nil {
+		gp.panicHandler(recovered, stack)
+		return
+	}
+	log.Printf("[GoPool] recovered panic: %v\n%+v", recovered, stack)
+}
@@ -3,7 +3,11 @@ package pool
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/andeya/goutil/status"
 )
 
 func TestGoPool(t *testing.T) {
@@ -82,6 +86,47 @@ func TestGoPool3(t *testing.T) {
 	}
 }
 
+func TestGoPoolMustGoWait(t *testing.T) {
+	gp := NewGoPool(1, 0)
+	defer gp.Stop()
+	block := make(chan struct{})
+	gp.MustGo(func() { <-block })
+
+	// The blocked callers wait for the freed goroutine instead of spinning.
+	var ran int32
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gp.MustGo(func() { atomic.AddInt32(&ran, 1) })
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	gp.lock.Lock()
+	waiting := gp.freeCh != nil
+	gp.lock.Unlock()
+	if !waiting || atomic.LoadInt32(&ran) != 0 {
+		t.Fatalf("expected the callers are waiting, got waiting=%v, ran=%d", waiting, ran)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := gp.MustGo(func() {}, ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	close(block)
+	wg.Wait()
+	// The tasks are accepted, wait for them to run.
+	for i := 0; i < 100 && atomic.LoadInt32(&ran) != 4; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&ran); n != 4 {
+		t.Fatalf("expected 4 tasks run, got %d", n)
+	}
+}
+
 func BenchmarkGoPool_MustGo(b *testing.B) {
 	gp := NewGoPool(10000000, 0)
 	wg := new(sync.WaitGroup)
@@ -133,3 +178,36 @@ func TestGoPool_Work(t *testing.T) {
 		t.Fatalf("except %d, but %d", 3, ret)
 	}
 }
+
+func TestGoPool_Panic(t *testing.T) {
+	var (
+		recovered interface{}
+		stack     status.StackTrace
+		hooked    int32
+		wg        sync.WaitGroup
+	)
+	gp := NewGoPool(1, 0,
+		WithPanicHandler(func(p interface{}, st status.StackTrace) {
+			recovered, stack = p, st
+		}),
+		WithTaskHooks(func() interface{} {
+			return "span"
+		}, func(state interface{}, cost time.Duration, panicked bool) {
+			if state == "span" && panicked {
+				atomic.AddInt32(&hooked, 1)
+			}
+			wg.Done()
+		}),
+	)
+	defer gp.Stop()
+	wg.Add(2)
+	gp.MustGo(func() { panic("oops") })
+	gp.MustGo(func() {})
+	wg.Wait()
+	if recovered != "oops" || len(stack) == 0 || atomic.LoadInt32(&hooked) != 1 {
+		t.Fatalf("recovered: %v, stack: %v, hooked: %d", recovered, stack, hooked)
+	}
+	if stats := gp.Stats(); stats.Panics != 1 || stats.Done != 2 || stats.Running != 0 || stats.Queued != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
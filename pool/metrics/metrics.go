@@ -0,0 +1,236 @@
+// Package metrics exports the statistics of ResPool, Workshop and GoPool
+// in the Prometheus text exposition format, without depending on the Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andeya/goutil/pool"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter collects the registered pools and renders their statistics.
+// It implements http.Handler.
+// It's safe for concurrent use by multiple goroutines.
+type Exporter struct {
+	namespace  string
+	lock       sync.RWMutex
+	collectors []collector
+}
+
+// collector appends the samples of a pool.
+type collector struct {
+	key     string
+	collect func(add func(m metric, labels string, value float64))
+}
+
+type metric struct {
+	name string
+	help string
+	typ  string
+}
+
+type sample struct {
+	labels string
+	value  float64
+}
+
+var (
+	resPoolOpen              = metric{"respool_open_resources", "The number of open resources.", "gauge"}
+	resPoolFree              = metric{"respool_free_resources", "The number of idle resources.", "gauge"}
+	resPoolClosed            = metric{"respool_closed_resources_total", "The total number of closed resources.", "counter"}
+	resPoolMaxIdleTimeClosed = metric{"respool_max_idle_time_closed_total", "The total number of resources closed due to max idle time.", "counter"}
+	resPoolMaxLifetimeClosed = metric{"respool_max_lifetime_closed_total", "The total number of resources closed due to max lifetime.", "counter"}
+	resPoolValidationFailed  = metric{"respool_validation_failed_total", "The total number of resources closed due to failed validation.", "counter"}
+
+	workshopWorkers = metric{"workshop_workers", "The current number of workers.", "gauge"}
+	workshopIdle    = metric{"workshop_idle_workers", "The current number of idle workers.", "gauge"}
+	workshopCreated = metric{"workshop_created_workers_total", "The total number of created workers.", "counter"}
+	workshopDoing   = metric{"workshop_doing_tasks", "The number of tasks in progress.", "gauge"}
+	workshopDone    = metric{"workshop_done_tasks_total", "The total number of completed tasks.", "counter"}
+	workshopMaxLoad = metric{"workshop_max_load", "The maximum number of tasks of a worker.", "gauge"}
+	workshopMinLoad = metric{"workshop_min_load", "The minimum number of tasks of a worker.", "gauge"}
+
+	goPoolGoroutines    = metric{"gopool_goroutines", "The current number of goroutines.", "gauge"}
+	goPoolIdle          = metric{"gopool_idle_goroutines", "The current number of idle goroutines.", "gauge"}
+	goPoolMaxGoroutines = metric{"gopool_max_goroutines", "The maximum number of goroutines.", "gauge"}
+	goPoolQueued        = metric{"gopool_queued_tasks", "The number of tasks waiting to run.", "gauge"}
+	goPoolRunning       = metric{"gopool_running_tasks", "The number of tasks in progress.", "gauge"}
+	goPoolDone          = metric{"gopool_done_tasks_total", "The total number of completed tasks.", "counter"}
+	goPoolPanics        = metric{"gopool_panics_total", "The total number of panicked tasks.", "counter"}
+	goPoolDropped       = metric{"gopool_dropped_tasks_total", "The total number of queued tasks dropped.", "counter"}
+	goPoolExpired       = metric{"gopool_expired_tasks_total", "The total number of queued tasks expired.", "counter"}
+)
+
+// NewExporter creates an exporter.
+// If namespace is not empty, it is the prefix of the metric names, such as "myapp".
+func NewExporter(namespace string) *Exporter {
+	if namespace != "" {
+		namespace += "_"
+	}
+	return &Exporter{namespace: namespace}
+}
+
+// RegisterResPools registers the ResPools collection,
+// all the ResPools in it at the time of rendering are exported, labeled with their names.
+// NOTE:
+//
+//	The ResPools with the same name are exported once, by the earliest registration,
+//	e.g. the ResPool also registered by RegisterResPool or in another ResPools.
+func (e *Exporter) RegisterResPools(resPools *pool.ResPools) {
+	e.register(fmt.Sprintf("respools:%p", resPools), func(add func(metric, string, float64)) {
+		for _, p := range resPools.GetAll() {
+			collectResPool(p, add)
+		}
+	})
+}
+
+// RegisterResPool registers the ResPool, labeled with its name.
+// If the same name is registered, replaces it;
+// if the name is also in a registered ResPools, it is exported once.
+func (e *Exporter) RegisterResPool(p pool.ResPool) {
+	e.register("respool:"+p.Name(), func(add func(metric, string, float64)) {
+		collectResPool(p, add)
+	})
+}
+
+// RegisterWorkshop registers the Workshop, labeled with the name.
+// If the same name is registered, replaces it.
+func (e *Exporter) RegisterWorkshop(name string, w *pool.Workshop) {
+	e.register("workshop:"+name, func(add func(metric, string, float64)) {
+		labels := poolLabel(name)
+		stats := w.Stats()
+		add(workshopWorkers, labels, float64(stats.Worker))
+		add(workshopIdle, labels, float64(stats.Idle))
+		add(workshopCreated, labels, float64(stats.Created))
+		add(workshopDoing, labels, float64(stats.Doing))
+		add(workshopDone, labels, float64(stats.Done))
+		add(workshopMaxLoad, labels, float64(stats.MaxLoad))
+		add(workshopMinLoad, labels, float64(stats.MinLoad))
+	})
+}
+
+// RegisterGoPool registers the GoPool, labeled with the name.
+// If the same name is registered, replaces it.
+func (e *Exporter) RegisterGoPool(name string, gp *pool.GoPool) {
+	e.register("gopool:"+name, func(add func(metric, string, float64)) {
+		labels := poolLabel(name)
+		stats := gp.Stats()
+		add(goPoolGoroutines, labels, float64(stats.Goroutines))
+		add(goPoolIdle, labels, float64(stats.Idle))
+		add(goPoolMaxGoroutines, labels, float64(stats.MaxGoroutines))
+		add(goPoolQueued, labels, float64(stats.Queued))
+		add(goPoolRunning, labels, float64(stats.Running))
+		add(goPoolDone, labels, float64(stats.Done))
+		add(goPoolPanics, labels, float64(stats.Panics))
+		add(goPoolDropped, labels, float64(stats.Dropped))
+		add(goPoolExpired, labels, float64(stats.Expired))
+	})
+}
+
+// Unregister unregisters all the pools.
+func (e *Exporter) Unregister() {
+	e.lock.Lock()
+	e.collectors = nil
+	e.lock.Unlock()
+}
+
+func (e *Exporter) register(key string, collect func(add func(metric, string, float64))) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for i, c := range e.collectors {
+		if c.key == key {
+			e.collectors[i].collect = collect
+			return
+		}
+	}
+	e.collectors = append(e.collectors, collector{key: key, collect: collect})
+}
+
+func collectResPool(p pool.ResPool, add func(metric, string, float64)) {
+	labels := poolLabel(p.Name())
+	stats := p.Stats()
+	add(resPoolOpen, labels, float64(stats.OpenResources))
+	add(resPoolFree, labels, float64(stats.FreeResources))
+	add(resPoolClosed, labels, float64(stats.ClosedResources))
+	add(resPoolMaxIdleTimeClosed, labels, float64(stats.MaxIdleTimeClosed))
+	add(resPoolMaxLifetimeClosed, labels, float64(stats.MaxLifetimeClosed))
+	add(resPoolValidationFailed, labels, float64(stats.ValidationFailed))
+}
+
+// WriteTo renders the statistics of all the registered pools to w.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	var names []string
+	var metrics = make(map[string]metric)
+	var samples = make(map[string][]sample)
+	var series = make(map[string]bool)
+	add := func(m metric, labels string, value float64) {
+		if series[m.name+labels] {
+			// The same pool is registered more than once.
+			return
+		}
+		series[m.name+labels] = true
+		if _, ok := metrics[m.name]; !ok {
+			names = append(names, m.name)
+			metrics[m.name] = m
+		}
+		samples[m.name] = append(samples[m.name], sample{labels: labels, value: value})
+	}
+	e.lock.RLock()
+	collectors := append([]collector(nil), e.collectors...)
+	e.lock.RUnlock()
+	for _, c := range collectors {
+		c.collect(add)
+	}
+	sort.Strings(names)
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, name := range names {
+		m := metrics[name]
+		fullName := e.namespace + m.name
+		bw.WriteString("# HELP " + fullName + " " + m.help + "\n")
+		bw.WriteString("# TYPE " + fullName + " " + m.typ + "\n")
+		for _, s := range samples[name] {
+			bw.WriteString(fullName)
+			bw.WriteString(s.labels)
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+			bw.WriteByte('\n')
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP renders the statistics of all the registered pools in response to the request.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	e.WriteTo(w)
+}
+
+func poolLabel(name string) string {
+	return `{pool="` + labelValueReplacer.Replace(name) + `"}`
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andeya/goutil/pool"
+)
+
+type testWorker struct{ int }
+
+func (*testWorker) Health() bool { return true }
+func (*testWorker) Close() error { return nil }
+
+func TestExporter(t *testing.T) {
+	resPools := pool.NewResPools()
+	resPools.Set(pool.NewResPool(`db"1`, func(context.Context) (pool.Resource, error) {
+		return nil, errors.New("unreachable")
+	}))
+	defer resPools.Clean()
+	w := pool.NewWorkshop(2, time.Minute, func() (pool.Worker, error) { return new(testWorker), nil })
+	defer w.Close()
+	w.Callback(func(pool.Worker) error { return nil })
+	gp := pool.NewGoPool(8, 0)
+	defer gp.Stop()
+
+	e := NewExporter("test")
+	e.RegisterResPools(resPools)
+	e.RegisterWorkshop("rpc", w)
+	e.RegisterGoPool("bg", gp)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	body := rec.Body.String()
+	t.Log(body)
+	for _, line := range []string{
+		"# TYPE test_respool_open_resources gauge\n",
+		`test_respool_open_resources{pool="db\"1"} 0` + "\n",
+		"# TYPE test_workshop_done_tasks_total counter\n",
+		`test_workshop_done_tasks_total{pool="rpc"} 1` + "\n",
+		`test_gopool_max_goroutines{pool="bg"} 8` + "\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q", line)
+		}
+	}
+}
+
+func TestExporterDuplicateResPool(t *testing.T) {
+	resPools := pool.NewResPools()
+	p := pool.NewResPool("db", func(context.Context) (pool.Resource, error) {
+		return nil, errors.New("unreachable")
+	})
+	resPools.Set(p)
+	defer resPools.Clean()
+
+	e := NewExporter("")
+	e.RegisterResPools(resPools)
+	e.RegisterResPool(p)
+	e.RegisterResPools(resPools)
+	var buf strings.Builder
+	if _, err := e.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	body := buf.String()
+	if n := strings.Count(body, `respool_open_resources{pool="db"}`); n != 1 {
+		t.Fatalf("expect the pool exported once, got %d times:\n%s", n, body)
+	}
+	if n := strings.Count(body, "# TYPE respool_open_resources gauge\n"); n != 1 {
+		t.Fatalf("expect the metric described once, got %d times:\n%s", n, body)
+	}
+}
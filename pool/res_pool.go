@@ -69,6 +69,29 @@ type ResPool interface {
 	Stats() ResPoolStats
 }
 
+// IdleResPool is the ResPool which also manages the idle time and the validation of its idle resources.
+// The ResPool created by NewResPool implements it, e.g.
+//
+//	p := NewResPool(name, newfunc).(IdleResPool)
+//	p.SetMaxIdleTime(time.Minute)
+type IdleResPool interface {
+	ResPool
+	// SetMaxIdleTime sets the maximum amount of time a resource may be idle.
+	//
+	// Expired resource may be closed lazily before reuse.
+	//
+	// If d <= 0, resource are not closed due to the resource's idle time.
+	SetMaxIdleTime(d time.Duration)
+	// SetValidator sets the function to check whether a resource is still usable.
+	//
+	// If onBorrow is true, an idle resource is validated before it is handed out;
+	// If idleInterval > 0, the idle resources are validated every idleInterval in the background.
+	// The resource that fails validation is closed.
+	//
+	// If validate is nil, no validation is done.
+	SetValidator(validate func(Resource) error, onBorrow bool, idleInterval time.Duration)
+}
+
 // Resource is a resource that can be stored in the ResPool.
 type Resource interface {
 	// SetAvatar stores the contact with resPool
@@ -89,7 +112,7 @@ type Resource interface {
 // to block until the resourceOpener can satisfy the backlog of requests.
 const avatarRequestQueueSize = 1000000
 
-// NewResPool creates ResPool.
+// NewResPool creates ResPool, which also implements IdleResPool.
 func NewResPool(name string, newfunc func(context.Context) (Resource, error)) ResPool {
 	p := &resPool{
 		newfunc:        newfunc,
@@ -132,10 +155,20 @@ type resPool struct {
 	maxIdle     int                // zero means defaultMaxIdle; negative means 0
 	maxOpen     int                // <= 0 means unlimited
 	maxLifetime time.Duration      // maximum amount of time a resource may be reused
+	maxIdleTime time.Duration      // maximum amount of time a resource may be idle before being closed
 	cleanerCh   chan struct{}
+
+	validate         func(Resource) error // checks whether a resource is still usable
+	validateOnBorrow bool                 // validates an idle resource before handing it out
+	validateInterval time.Duration        // interval of validating the idle resources
+	lastValidated    time.Time            // the last time the idle resources were validated
+
+	maxIdleTimeClosed uint64 // Total number of resources closed due to idle time.
+	maxLifetimeClosed uint64 // Total number of resources closed due to max resource lifetime limit.
+	validationFailed  uint64 // Total number of resources closed due to failed validation.
 }
 
-var _ ResPool = new(resPool)
+var _ IdleResPool = new(resPool)
 
 // resourceReuseStrategy determines how (*resPool).getone returns resources.
 type resourceReuseStrategy uint8
@@ -152,8 +185,9 @@ const (
 // Avatar links a Resource with a mutex, to
 // be held during all calls into the Avatar.
 type Avatar struct {
-	p         *resPool
-	createdAt time.Time
+	p          *resPool
+	createdAt  time.Time
+	returnedAt time.Time // Time the resource was created or returned.
 
 	lock        sync.Mutex // guards following
 	res         Resource
@@ -182,6 +216,14 @@ func (avatar *Avatar) expired(timeout time.Duration) bool {
 	return avatar.createdAt.Add(timeout).Before(coarsetime.FloorTimeNow())
 }
 
+// the avatar.p's Mutex is held.
+func (avatar *Avatar) idleExpiredLocked(timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	return avatar.returnedAt.Add(timeout).Before(coarsetime.FloorTimeNow())
+}
+
 // the avatar.p's Mutex is held.
 func (avatar *Avatar) closeResPoolLocked() func() error {
 	avatar.lock.Lock()
@@ -391,23 +433,87 @@ func (p *resPool) SetMaxLifetime(d time.Duration) {
 		d = 0
 	}
 	p.mu.Lock()
-	// wake cleaner up when lifetime is shortened.
-	if d > 0 && d < p.maxLifetime && p.cleanerCh != nil {
+	old := p.shortestIdleTimeLocked()
+	p.maxLifetime = d
+	p.wakeCleanerLocked(old)
+	p.startCleanerLocked()
+	p.mu.Unlock()
+}
+
+// SetMaxIdleTime sets the maximum amount of time a resource may be idle.
+//
+// Expired resource may be closed lazily before reuse.
+//
+// If d <= 0, resource are not closed due to the resource's idle time.
+func (p *resPool) SetMaxIdleTime(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	p.mu.Lock()
+	old := p.shortestIdleTimeLocked()
+	p.maxIdleTime = d
+	p.wakeCleanerLocked(old)
+	p.startCleanerLocked()
+	p.mu.Unlock()
+}
+
+// SetValidator sets the function to check whether a resource is still usable.
+//
+// If onBorrow is true, an idle resource is validated before it is handed out;
+// If idleInterval > 0, the idle resources are validated every idleInterval in the background.
+// The resource that fails validation is closed.
+//
+// If validate is nil, no validation is done.
+func (p *resPool) SetValidator(validate func(Resource) error, onBorrow bool, idleInterval time.Duration) {
+	if idleInterval < 0 {
+		idleInterval = 0
+	}
+	p.mu.Lock()
+	old := p.shortestIdleTimeLocked()
+	p.validate = validate
+	p.validateOnBorrow = onBorrow
+	p.validateInterval = idleInterval
+	p.wakeCleanerLocked(old)
+	p.startCleanerLocked()
+	p.mu.Unlock()
+}
+
+// shortestIdleTimeLocked returns the interval of resourceCleaner,
+// which is the minimum of maxLifetime, maxIdleTime and validateInterval.
+// If returns 0, resourceCleaner is not needed.
+func (p *resPool) shortestIdleTimeLocked() time.Duration {
+	var min time.Duration
+	for _, d := range [...]time.Duration{p.maxLifetime, p.maxIdleTime, p.validateIntervalLocked()} {
+		if d > 0 && (min == 0 || d < min) {
+			min = d
+		}
+	}
+	return min
+}
+
+func (p *resPool) validateIntervalLocked() time.Duration {
+	if p.validate == nil {
+		return 0
+	}
+	return p.validateInterval
+}
+
+// wakeCleanerLocked wakes cleaner up when its interval is shortened.
+func (p *resPool) wakeCleanerLocked(old time.Duration) {
+	d := p.shortestIdleTimeLocked()
+	if d > 0 && d < old && p.cleanerCh != nil {
 		select {
 		case p.cleanerCh <- struct{}{}:
 		default:
 		}
 	}
-	p.maxLifetime = d
-	p.startCleanerLocked()
-	p.mu.Unlock()
 }
 
 // startCleanerLocked starts resourceCleaner if needed.
 func (p *resPool) startCleanerLocked() {
-	if p.maxLifetime > 0 && p.numOpen > 0 && p.cleanerCh == nil {
+	if d := p.shortestIdleTimeLocked(); d > 0 && p.numOpen > 0 && p.cleanerCh == nil {
 		p.cleanerCh = make(chan struct{}, 1)
-		go p.resourceCleaner(p.maxLifetime)
+		go p.resourceCleaner(d)
 	}
 }
 
@@ -426,31 +532,50 @@ func (p *resPool) resourceCleaner(d time.Duration) {
 		}
 
 		p.mu.Lock()
-		d = p.maxLifetime
+		d = p.shortestIdleTimeLocked()
 		if p.closed || p.numOpen == 0 || d <= 0 {
 			p.cleanerCh = nil
 			p.mu.Unlock()
 			return
 		}
 
-		expiredSince := coarsetime.FloorTimeNow().Add(-d)
-		var closing []*Avatar
+		now := coarsetime.FloorTimeNow()
+		validate := p.validate
+		validateDue := validate != nil && p.validateInterval > 0 &&
+			!now.Before(p.lastValidated.Add(p.validateInterval))
+		if validateDue {
+			p.lastValidated = now
+		}
+		var closing, checking []*Avatar
 		for i := 0; i < len(p.freeAvatar); i++ {
 			c := p.freeAvatar[i]
-			if c.createdAt.Before(expiredSince) {
+			switch {
+			case p.maxLifetime > 0 && c.createdAt.Before(now.Add(-p.maxLifetime)):
+				p.maxLifetimeClosed++
 				closing = append(closing, c)
-				last := len(p.freeAvatar) - 1
-				p.freeAvatar[i] = p.freeAvatar[last]
-				p.freeAvatar[last] = nil
-				p.freeAvatar = p.freeAvatar[:last]
-				i--
+			case p.maxIdleTime > 0 && c.returnedAt.Before(now.Add(-p.maxIdleTime)):
+				p.maxIdleTimeClosed++
+				closing = append(closing, c)
+			case validateDue:
+				// Take it out of the idle pool while validating.
+				checking = append(checking, c)
+			default:
+				continue
 			}
+			last := len(p.freeAvatar) - 1
+			p.freeAvatar[i] = p.freeAvatar[last]
+			p.freeAvatar[last] = nil
+			p.freeAvatar = p.freeAvatar[:last]
+			i--
 		}
 		p.mu.Unlock()
 
 		for _, c := range closing {
 			c.close()
 		}
+		for _, c := range checking {
+			p.validateIdle(c, validate)
+		}
 
 		if d < minInterval {
 			d = minInterval
@@ -459,21 +584,46 @@ func (p *resPool) resourceCleaner(d time.Duration) {
 	}
 }
 
+// validateIdle validates the idle resource taken out of the idle pool,
+// and puts it back if it is valid, otherwise closes it.
+func (p *resPool) validateIdle(c *Avatar, validate func(Resource) error) {
+	if err := validate(c.res); err != nil {
+		p.mu.Lock()
+		p.validationFailed++
+		p.mu.Unlock()
+		c.close()
+		return
+	}
+	p.mu.Lock()
+	added := p.putResPoolLocked(c, nil)
+	p.mu.Unlock()
+	if !added {
+		c.close()
+	}
+}
+
 // ResPoolStats contains resource statistics.
 type ResPoolStats struct {
 	// OpenResources is the number of open resources to the resource.
 	OpenResources   int
 	FreeResources   int
 	ClosedResources uint64
+
+	MaxIdleTimeClosed uint64 // The total number of resources closed due to SetMaxIdleTime.
+	MaxLifetimeClosed uint64 // The total number of resources closed due to SetMaxLifetime.
+	ValidationFailed  uint64 // The total number of resources closed due to failed validation.
 }
 
 // Stats returns resource statistics.
 func (p *resPool) Stats() ResPoolStats {
 	p.mu.Lock()
 	stats := ResPoolStats{
-		OpenResources:   p.numOpen,
-		ClosedResources: p.numClosed,
-		FreeResources:   len(p.freeAvatar),
+		OpenResources:     p.numOpen,
+		ClosedResources:   atomic.LoadUint64(&p.numClosed),
+		FreeResources:     len(p.freeAvatar),
+		MaxIdleTimeClosed: p.maxIdleTimeClosed,
+		MaxLifetimeClosed: p.maxLifetimeClosed,
+		ValidationFailed:  p.validationFailed,
 	}
 	p.mu.Unlock()
 	return stats
@@ -529,10 +679,12 @@ func (p *resPool) openNewResource(ctx context.Context) {
 		p.maybeOpenNewResources()
 		return
 	}
+	now := coarsetime.FloorTimeNow()
 	avatar := &Avatar{
-		p:         p,
-		createdAt: coarsetime.FloorTimeNow(),
-		res:       res,
+		p:          p,
+		createdAt:  now,
+		returnedAt: now,
+		res:        res,
 	}
 	res.SetAvatar(avatar)
 	if p.putResPoolLocked(avatar, err) {
@@ -636,11 +788,32 @@ func (p *resPool) getone(ctx context.Context, strategy resourceReuseStrategy) (R
 		copy(p.freeAvatar, p.freeAvatar[1:])
 		p.freeAvatar = p.freeAvatar[:numFree-1]
 		a.inUse = true
-		p.mu.Unlock()
 		if a.expired(lifetime) {
+			p.maxLifetimeClosed++
+			p.mu.Unlock()
+			a.close()
+			return nil, ErrExpired
+		}
+		if a.idleExpiredLocked(p.maxIdleTime) {
+			p.maxIdleTimeClosed++
+			p.mu.Unlock()
 			a.close()
 			return nil, ErrExpired
 		}
+		var validate func(Resource) error
+		if p.validateOnBorrow {
+			validate = p.validate
+		}
+		p.mu.Unlock()
+		if validate != nil {
+			if err := validate(a.res); err != nil {
+				p.mu.Lock()
+				p.validationFailed++
+				p.mu.Unlock()
+				a.close()
+				return nil, err
+			}
+		}
 		return a.res, nil
 	}
 	// Out of free resources or we were asked not to use one. If we're not
@@ -675,6 +848,9 @@ func (p *resPool) getone(ctx context.Context, strategy resourceReuseStrategy) (R
 			}
 			if ret.err == nil {
 				if ret.avatar.expired(lifetime) {
+					p.mu.Lock()
+					p.maxLifetimeClosed++
+					p.mu.Unlock()
 					ret.avatar.close()
 					return nil, ErrExpired
 				}
@@ -695,10 +871,12 @@ func (p *resPool) getone(ctx context.Context, strategy resourceReuseStrategy) (R
 		return nil, err
 	}
 	p.mu.Lock()
+	now := coarsetime.FloorTimeNow()
 	avatar := &Avatar{
-		p:         p,
-		createdAt: coarsetime.FloorTimeNow(),
-		res:       res,
+		p:          p,
+		createdAt:  now,
+		returnedAt: now,
+		res:        res,
 	}
 	res.SetAvatar(avatar)
 	p.addDepLocked(avatar, avatar)
@@ -749,6 +927,7 @@ func (p *resPool) putAvatar(avatar *Avatar, err error) {
 		p.lastPut[avatar] = stack()
 	}
 	avatar.inUse = false
+	avatar.returnedAt = coarsetime.FloorTimeNow()
 
 	if putAvatarHook != nil {
 		putAvatarHook(p, avatar)
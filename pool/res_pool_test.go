@@ -3,7 +3,9 @@ package pool
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestResPool(t *testing.T) {
@@ -13,3 +15,66 @@ func TestResPool(t *testing.T) {
 	res, err := p.Get()
 	t.Logf("res: %#v, err: %v", res, err)
 }
+
+type testResource struct {
+	avatar *Avatar
+	closed int32
+}
+
+func (r *testResource) SetAvatar(avatar *Avatar) { r.avatar = avatar }
+func (r *testResource) GetAvatar() *Avatar       { return r.avatar }
+func (r *testResource) Close() error             { atomic.StoreInt32(&r.closed, 1); return nil }
+
+func TestResPoolMaxIdleTime(t *testing.T) {
+	p := NewResPool("idle", func(context.Context) (Resource, error) {
+		return new(testResource), nil
+	}).(IdleResPool)
+	defer p.Close()
+	p.SetMaxIdleTime(time.Millisecond * 100)
+	res, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(res, nil)
+	time.Sleep(time.Millisecond * 300)
+	res2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res2 == res {
+		t.Fatal("expect the idle expired resource closed")
+	}
+	p.Put(res2, nil)
+	if stats := p.Stats(); stats.MaxIdleTimeClosed != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// The idle resource failing validation is evicted in the background without being borrowed.
+func TestResPoolValidateIdle(t *testing.T) {
+	p := NewResPool("validate", func(context.Context) (Resource, error) {
+		return new(testResource), nil
+	}).(IdleResPool)
+	defer p.Close()
+	res, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(res, nil)
+	p.SetValidator(func(Resource) error {
+		return errors.New("dead resource")
+	}, false, time.Millisecond*10)
+	for deadline := time.Now().Add(time.Second * 3); p.Stats().ClosedResources == 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expect the idle resource evicted, got stats: %+v", p.Stats())
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+	stats := p.Stats()
+	if stats.ValidationFailed != 1 || stats.FreeResources != 0 || stats.OpenResources != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if atomic.LoadInt32(&res.(*testResource).closed) != 1 {
+		t.Fatal("expect the idle resource closed")
+	}
+}
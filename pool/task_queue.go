@@ -0,0 +1,160 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Priority is the priority of a task waiting in the GoPool queue.
+type Priority uint8
+
+// The priority lanes, the higher priority tasks jump ahead of the lower ones.
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	priorityLanes
+)
+
+// RejectPolicy decides what to do with a new task when the GoPool queue is full.
+type RejectPolicy uint8
+
+const (
+	// RejectFail fails the new task with ErrQueueFull.
+	RejectFail RejectPolicy = iota
+	// RejectCallerRuns runs the new task synchronously in the caller's goroutine.
+	RejectCallerRuns
+	// RejectDropOldest drops the oldest waiting task with the lowest priority,
+	// which is not higher than the new one, and then queues the new task.
+	// If there is no such task, fails the new task with ErrQueueFull.
+	RejectDropOldest
+)
+
+// ErrQueueFull error: the GoPool wait queue is full.
+var ErrQueueFull = errors.New("the wait queue of goroutines is full")
+
+// ErrQueueExpired error: the task waited in the GoPool queue longer than the max wait time.
+var ErrQueueExpired = errors.New("the task expired in the wait queue of goroutines")
+
+// errCallerRuns tells the caller to run the task by itself.
+var errCallerRuns = errors.New("caller runs")
+
+// taskQueue is the FIFO wait queue with priority lanes, guarded by GoPool.lock.
+type taskQueue struct {
+	lanes   [priorityLanes][]queuedTask
+	length  int
+	size    int
+	maxWait time.Duration
+	policy  RejectPolicy
+}
+
+type queuedTask struct {
+	fn func()
+	// reject is called with ErrQueueFull or ErrQueueExpired when fn is dropped or expired,
+	// it is called with GoPool.lock held, so it must not call the GoPool.
+	reject   func(error)
+	deadline time.Time // zero means no deadline
+}
+
+// discard counts the task as dropped or expired, and notifies its reject callback.
+func (t queuedTask) discard(gp *GoPool, err error) {
+	atomic.AddInt32(&gp.queued, -1)
+	gp.notifyFreeLocked()
+	if err == ErrQueueExpired {
+		atomic.AddUint64(&gp.expired, 1)
+	} else {
+		atomic.AddUint64(&gp.dropped, 1)
+	}
+	if t.reject != nil {
+		t.reject(err)
+	}
+}
+
+func (q *taskQueue) pushLocked(gp *GoPool, fn func(), reject func(error), priority Priority) error {
+	if priority >= priorityLanes {
+		priority = PriorityHigh
+	}
+	if q.length >= q.size {
+		q.purgeExpiredLocked(gp)
+	}
+	if q.length >= q.size {
+		switch q.policy {
+		case RejectCallerRuns:
+			return errCallerRuns
+		case RejectDropOldest:
+			if !q.dropOldestLocked(gp, priority) {
+				return ErrQueueFull
+			}
+		default:
+			return ErrQueueFull
+		}
+	}
+	t := queuedTask{fn: fn, reject: reject}
+	if q.maxWait > 0 {
+		t.deadline = time.Now().Add(q.maxWait)
+	}
+	q.lanes[priority] = append(q.lanes[priority], t)
+	q.length++
+	atomic.AddInt32(&gp.queued, 1)
+	return nil
+}
+
+// popLocked pops the next task which is not expired, returns nil if the queue is empty.
+func (q *taskQueue) popLocked(gp *GoPool) func() {
+	if q.length == 0 {
+		return nil
+	}
+	var now time.Time
+	if q.maxWait > 0 {
+		now = time.Now()
+	}
+	for p := int(priorityLanes) - 1; p >= 0; p-- {
+		for len(q.lanes[p]) > 0 {
+			t := q.shiftLocked(Priority(p))
+			if !t.deadline.IsZero() && now.After(t.deadline) {
+				t.discard(gp, ErrQueueExpired)
+				continue
+			}
+			return t.fn
+		}
+	}
+	return nil
+}
+
+func (q *taskQueue) shiftLocked(priority Priority) queuedTask {
+	lane := q.lanes[priority]
+	t := lane[0]
+	lane[0] = queuedTask{}
+	if len(lane) == 1 {
+		q.lanes[priority] = lane[:0]
+	} else {
+		q.lanes[priority] = lane[1:]
+	}
+	q.length--
+	return t
+}
+
+// purgeExpiredLocked removes the expired tasks at the head of the lanes.
+func (q *taskQueue) purgeExpiredLocked(gp *GoPool) {
+	if q.maxWait <= 0 {
+		return
+	}
+	now := time.Now()
+	for p := range q.lanes {
+		for len(q.lanes[p]) > 0 && now.After(q.lanes[p][0].deadline) {
+			q.shiftLocked(Priority(p)).discard(gp, ErrQueueExpired)
+		}
+	}
+}
+
+// dropOldestLocked drops the oldest task with the lowest priority not higher than the given one.
+func (q *taskQueue) dropOldestLocked(gp *GoPool, priority Priority) bool {
+	for p := Priority(0); p <= priority; p++ {
+		if len(q.lanes[p]) > 0 {
+			q.shiftLocked(p).discard(gp, ErrQueueFull)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockGoPool occupies all the goroutines of gp until the returned function is called.
+func blockGoPool(t *testing.T, gp *GoPool) func() {
+	block := make(chan struct{})
+	for i := 0; i < gp.MaxGoroutinesAmount(); i++ {
+		if err := gp.Go(func() { <-block }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() { close(block) }
+}
+
+func TestGoPoolQueuePriority(t *testing.T) {
+	gp := NewGoPool(1, 0, WithQueue(10, 0, RejectFail))
+	defer gp.Stop()
+	unblock := blockGoPool(t, gp)
+	var lock sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityNormal} {
+		i := i
+		wg.Add(1)
+		err := gp.GoPriority(p, func() {
+			lock.Lock()
+			order = append(order, i)
+			lock.Unlock()
+			wg.Done()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := gp.Stats(); stats.Queued != 4 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	unblock()
+	wg.Wait()
+	expect := []int{2, 1, 3, 0}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("expect %v, got %v", expect, order)
+		}
+	}
+}
+
+func TestGoPoolQueueReject(t *testing.T) {
+	gp := NewGoPool(1, 0, WithQueue(1, 0, RejectFail))
+	unblock := blockGoPool(t, gp)
+	if err := gp.Go(func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gp.Go(func() {}); err != ErrQueueFull {
+		t.Fatalf("expect ErrQueueFull, got %v", err)
+	}
+	unblock()
+	gp.Stop()
+
+	gp = NewGoPool(1, 0, WithQueue(1, 0, RejectCallerRuns))
+	unblock = blockGoPool(t, gp)
+	gp.Go(func() {})
+	var ran bool
+	if err := gp.Go(func() { ran = true }); err != nil || !ran {
+		t.Fatalf("expect running in caller, got %v", err)
+	}
+	unblock()
+	gp.Stop()
+
+	gp = NewGoPool(1, 0, WithQueue(1, 0, RejectDropOldest))
+	unblock = blockGoPool(t, gp)
+	gp.Go(func() { t.Error("the oldest task should be dropped") })
+	done := make(chan struct{})
+	if err := gp.Go(func() { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	unblock()
+	<-done
+	if stats := gp.Stats(); stats.Dropped != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	gp.Stop()
+}
+
+func TestGoPoolQueueMaxWait(t *testing.T) {
+	gp := NewGoPool(1, 0, WithQueue(10, time.Millisecond*10, RejectFail))
+	defer gp.Stop()
+	unblock := blockGoPool(t, gp)
+	gp.Go(func() { t.Error("the expired task should not run") })
+	time.Sleep(time.Millisecond * 50)
+	done := make(chan struct{})
+	gp.Go(func() { close(done) })
+	unblock()
+	<-done
+	if stats := gp.Stats(); stats.Expired != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSubmitQueueRejected(t *testing.T) {
+	gp := NewGoPool(1, 0, WithQueue(1, 0, RejectDropOldest))
+	defer gp.Stop()
+	unblock := blockGoPool(t, gp)
+	dropped := Submit(context.Background(), gp, func(context.Context) (int, error) { return 1, nil })
+	queued := Submit(context.Background(), gp, func(context.Context) (int, error) { return 2, nil })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := dropped.GetContext(ctx); err != ErrQueueFull {
+		t.Fatalf("expect ErrQueueFull, got %v", err)
+	}
+	unblock()
+	if v, err := queued.GetContext(ctx); v != 2 || err != nil {
+		t.Fatalf("expect 2, got %d, %v", v, err)
+	}
+
+	gp2 := NewGoPool(1, 0, WithQueue(4, time.Millisecond*10, RejectFail))
+	defer gp2.Stop()
+	unblock = blockGoPool(t, gp2)
+	expired := Submit(context.Background(), gp2, func(context.Context) (int, error) { return 1, nil })
+	time.Sleep(time.Millisecond * 50)
+	unblock()
+	if _, err := expired.GetContext(ctx); err != ErrQueueExpired {
+		t.Fatalf("expect ErrQueueExpired, got %v", err)
+	}
+}
+
+func TestGroupQueueRejected(t *testing.T) {
+	gp := NewGoPool(1, 0, WithQueue(4, time.Millisecond*10, RejectFail))
+	defer gp.Stop()
+	unblock := blockGoPool(t, gp)
+	g, _ := NewGroup(context.Background(), gp)
+	for i := 0; i < 2; i++ {
+		g.Go(func(context.Context) error {
+			t.Error("the expired task should not run")
+			return nil
+		})
+	}
+	time.Sleep(time.Millisecond * 50)
+	unblock()
+	waitGroup(t, g, ErrQueueExpired)
+
+	gp2 := NewGoPool(1, 0, WithQueue(1, 0, RejectDropOldest))
+	defer gp2.Stop()
+	unblock = blockGoPool(t, gp2)
+	g, _ = NewGroup(context.Background(), gp2)
+	g.Go(func(context.Context) error {
+		t.Error("the dropped task should not run")
+		return nil
+	})
+	g.Go(func(context.Context) error { return nil })
+	unblock()
+	waitGroup(t, g, ErrQueueFull)
+}
+
+// waitGroup waits for the group with a timeout, and checks its error.
+func waitGroup(t *testing.T, g *Group, expect error) {
+	done := make(chan error, 1)
+	go func() { done <- g.Wait() }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, expect) {
+			t.Fatalf("expect %v, got %v", expect, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the group never finishes")
+	}
+}
@@ -0,0 +1,193 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// TypedResPool is the generic version of ResPool, which yields the resource in type T directly,
+// so the resource need not implement the SetAvatar/GetAvatar plumbing.
+//
+// It shares the implementation of ResPool, including the request queue,
+// the max-open/max-idle/lifetime semantics and ResPoolStats.
+type TypedResPool[T comparable] interface {
+	// Name returns the name.
+	Name() string
+	// Get returns a resource.
+	Get() (T, error)
+	// GetContext returns a resource.
+	// Support context cancellation.
+	GetContext(context.Context) (T, error)
+	// Put gives a resource back to the TypedResPool.
+	// If error is not nil, close the resource.
+	Put(T, error)
+	// Callback callbacks your handle function, returns the error of getting resource or handling.
+	// Support recover panic.
+	Callback(func(T) error) error
+	// CallbackContext callbacks your handle function, returns the error of getting resource or handling.
+	// Support recover panic and context cancellation.
+	CallbackContext(context.Context, func(T) error) error
+	// SetMaxLifetime sets the maximum amount of time a resource may be reused.
+	//
+	// If d <= 0, resource are reused forever.
+	SetMaxLifetime(d time.Duration)
+	// SetMaxIdleTime sets the maximum amount of time a resource may be idle.
+	//
+	// If d <= 0, resource are not closed due to the resource's idle time.
+	SetMaxIdleTime(d time.Duration)
+	// SetValidator sets the function to check whether a resource is still usable.
+	//
+	// If onBorrow is true, an idle resource is validated before it is handed out;
+	// If idleInterval > 0, the idle resources are validated every idleInterval in the background.
+	//
+	// If validate is nil, no validation is done.
+	SetValidator(validate func(T) error, onBorrow bool, idleInterval time.Duration)
+	// SetMaxIdle sets the maximum number of resources in the idle
+	// resource pool.
+	//
+	// If n <= 0, no idle resources are retained.
+	SetMaxIdle(n int)
+	// SetMaxOpen sets the maximum number of open resources.
+	//
+	// If n <= 0, then there is no limit on the number of open resources.
+	// The default is 0 (unlimited).
+	SetMaxOpen(n int)
+	// Close closes the TypedResPool, releasing any open resources.
+	Close() error
+	// Stats returns resource statistics.
+	Stats() ResPoolStats
+	// Untyped returns the underlying ResPool, e.g. for registering to ResPools.
+	Untyped() ResPool
+}
+
+// NewTypedResPool creates TypedResPool.
+// NOTE:
+//
+//	newfunc must return distinct values, such as pointers, for different resources;
+//	closefunc closes the resource, if it is nil and T implements io.Closer, use T.Close.
+func NewTypedResPool[T comparable](name string, newfunc func(context.Context) (T, error), closefunc func(T) error) TypedResPool[T] {
+	if closefunc == nil {
+		closefunc = func(v T) error {
+			if c, ok := interface{}(v).(io.Closer); ok {
+				return c.Close()
+			}
+			return nil
+		}
+	}
+	p := &typedResPool[T]{
+		closefunc: closefunc,
+		borrowed:  make(map[T]*typedResource[T]),
+	}
+	p.ResPool = NewResPool(name, func(ctx context.Context) (Resource, error) {
+		v, err := newfunc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &typedResource[T]{value: v, closefunc: closefunc}, nil
+	})
+	return p
+}
+
+type typedResPool[T comparable] struct {
+	ResPool
+	closefunc func(T) error
+	mu        sync.Mutex // protects borrowed
+	borrowed  map[T]*typedResource[T]
+}
+
+var _ TypedResPool[*struct{}] = (*typedResPool[*struct{}])(nil)
+
+// typedResource adapts the value in type T to Resource.
+type typedResource[T comparable] struct {
+	value     T
+	avatar    *Avatar
+	closefunc func(T) error
+}
+
+func (r *typedResource[T]) SetAvatar(avatar *Avatar) { r.avatar = avatar }
+
+func (r *typedResource[T]) GetAvatar() *Avatar { return r.avatar }
+
+func (r *typedResource[T]) Close() error { return r.closefunc(r.value) }
+
+// Untyped returns the underlying ResPool.
+func (p *typedResPool[T]) Untyped() ResPool {
+	return p.ResPool
+}
+
+// Get returns a resource.
+func (p *typedResPool[T]) Get() (T, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext returns a resource, support context cancellation.
+func (p *typedResPool[T]) GetContext(ctx context.Context) (T, error) {
+	res, err := p.ResPool.GetContext(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	r := res.(*typedResource[T])
+	p.mu.Lock()
+	p.borrowed[r.value] = r
+	p.mu.Unlock()
+	return r.value, nil
+}
+
+// Put gives a resource back to the TypedResPool.
+// If error is not nil, close the resource.
+func (p *typedResPool[T]) Put(v T, err error) {
+	p.mu.Lock()
+	r, ok := p.borrowed[v]
+	delete(p.borrowed, v)
+	p.mu.Unlock()
+	if !ok {
+		// Does not belong to the pool.
+		p.closefunc(v)
+		return
+	}
+	p.ResPool.Put(r, err)
+}
+
+// SetMaxIdleTime sets the maximum amount of time a resource may be idle.
+func (p *typedResPool[T]) SetMaxIdleTime(d time.Duration) {
+	p.ResPool.(IdleResPool).SetMaxIdleTime(d)
+}
+
+// SetValidator sets the function to check whether a resource is still usable.
+func (p *typedResPool[T]) SetValidator(validate func(T) error, onBorrow bool, idleInterval time.Duration) {
+	idle := p.ResPool.(IdleResPool)
+	if validate == nil {
+		idle.SetValidator(nil, onBorrow, idleInterval)
+		return
+	}
+	idle.SetValidator(func(res Resource) error {
+		return validate(res.(*typedResource[T]).value)
+	}, onBorrow, idleInterval)
+}
+
+// Callback callbacks your handle function, returns the error of getting resource or handling.
+// Support recover panic.
+func (p *typedResPool[T]) Callback(fn func(T) error) error {
+	return p.CallbackContext(context.Background(), fn)
+}
+
+// CallbackContext callbacks your handle function, returns the error of getting resource or handling.
+// Support recover panic and context cancellation.
+func (p *typedResPool[T]) CallbackContext(ctx context.Context, fn func(T) error) (err error) {
+	v, err := p.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		p.Put(v, err)
+	}()
+	err = fn(v)
+	return err
+}
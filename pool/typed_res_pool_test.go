@@ -0,0 +1,82 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+type testConn struct {
+	id     int32
+	closed bool
+}
+
+func (c *testConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTypedResPool(t *testing.T) {
+	var id int32
+	p := NewTypedResPool("typed", func(context.Context) (*testConn, error) {
+		return &testConn{id: atomic.AddInt32(&id, 1)}, nil
+	}, nil)
+	defer p.Close()
+	p.SetMaxOpen(2)
+
+	c1, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 == c2 {
+		t.Fatal("expect different resources")
+	}
+	p.Put(c1, nil)
+	err = p.Callback(func(c *testConn) error {
+		if c != c1 {
+			t.Fatalf("expect reusing resource %d, got %d", c1.id, c.id)
+		}
+		return errors.New("bad resource")
+	})
+	if err == nil || !c1.closed {
+		t.Fatalf("expect the bad resource closed, got err=%v", err)
+	}
+	p.Put(c2, nil)
+	stats := p.Stats()
+	if stats.OpenResources != 1 || stats.ClosedResources != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTypedResPoolValidator(t *testing.T) {
+	var id int32
+	p := NewTypedResPool("validator", func(context.Context) (*testConn, error) {
+		return &testConn{id: atomic.AddInt32(&id, 1)}, nil
+	}, nil)
+	defer p.Close()
+	p.SetValidator(func(c *testConn) error {
+		if c.id == 1 {
+			return errors.New("dead connection")
+		}
+		return nil
+	}, true, 0)
+
+	c, err := p.Get()
+	if err != nil || c.id != 1 {
+		t.Fatalf("got %v, %v", c, err)
+	}
+	p.Put(c, nil)
+	c, err = p.Get()
+	if err != nil || c.id != 2 {
+		t.Fatalf("expect a new resource, got %v, %v", c, err)
+	}
+	p.Put(c, nil)
+	if stats := p.Stats(); stats.ValidationFailed != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
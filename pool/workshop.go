@@ -39,7 +39,10 @@ type (
 		maxQuota        int
 		maxIdleDuration time.Duration
 		infos           map[Worker]*workerInfo
+		infoList        []*workerInfo // in the order of creation
 		minLoadInfo     *workerInfo
+		balancer        Balancer
+		loads           []WorkerLoad // scratch space for balancer
 		stats           *WorkshopStats
 		statsReader     atomic.Value
 		lock            sync.Mutex
@@ -51,7 +54,11 @@ type (
 		worker     Worker
 		jobNum     int32
 		idleExpire time.Time
+		done       uint64
+		latency    time.Duration
 	}
+	// WorkshopOption is an option of NewWorkshop.
+	WorkshopOption func(*Workshop)
 	// WorkshopStats workshop stats
 	WorkshopStats struct {
 		Worker  int32  // The current total number of workers
@@ -67,6 +74,8 @@ type (
 const (
 	defaultWorkerMaxQuota        = 64
 	defaultWorkerMaxIdleDuration = 3 * time.Minute
+	// latencyDecay is the smoothing divisor of the worker latency average.
+	latencyDecay = 5
 )
 
 var (
@@ -74,11 +83,19 @@ var (
 	ErrWorkshopClosed = fmt.Errorf("%s", "workshop is closed")
 )
 
+// WithBalancer sets the strategy of selecting a worker when hiring.
+// If balancer is nil, the worker with the fewest tasks is selected.
+func WithBalancer(balancer Balancer) WorkshopOption {
+	return func(w *Workshop) {
+		w.balancer = balancer
+	}
+}
+
 // NewWorkshop creates a new workshop(non-blocking asynchronous multiplex resource pool).
 // If maxQuota<=0, will use default value.
 // If maxIdleDuration<=0, will use default value.
 // Note: Worker can not be implemented using empty structures(struct{})!
-func NewWorkshop(maxQuota int, maxIdleDuration time.Duration, newWorkerFunc func() (Worker, error)) *Workshop {
+func NewWorkshop(maxQuota int, maxIdleDuration time.Duration, newWorkerFunc func() (Worker, error), opts ...WorkshopOption) *Workshop {
 	if maxQuota <= 0 {
 		maxQuota = defaultWorkerMaxQuota
 	}
@@ -106,10 +123,14 @@ func NewWorkshop(maxQuota int, maxIdleDuration time.Duration, newWorkerFunc func
 			worker: worker,
 		}
 		w.infos[worker] = info
+		w.infoList = append(w.infoList, info)
 		w.stats.Created++
 		w.stats.Worker++
 		return info, nil
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
 	go w.gc()
 	return w
 }
@@ -128,15 +149,18 @@ func (w *Workshop) Callback(fn func(Worker) error) (err error) {
 		return err
 	}
 	worker := info.worker
+	start := time.Now()
 	defer func() {
 		if p := recover(); p != nil {
 			err = fmt.Errorf("%v", p)
 		}
+		cost := time.Since(start)
 		w.lock.Lock()
 		_, ok := w.infos[worker]
 		if !ok {
 			worker.Close()
 		} else {
+			info.observeLatency(cost)
 			w.fireLocked(info)
 		}
 		w.lock.Unlock()
@@ -162,6 +186,7 @@ func (w *Workshop) Close() {
 		info.worker.Close()
 	}
 	w.infos = nil
+	w.infoList = nil
 	w.stats.Idle = 0
 	w.stats.Worker = 0
 	w.refreshLocked(true)
@@ -210,6 +235,7 @@ func (w *Workshop) fireLocked(info *workerInfo) {
 		w.stats.Doing--
 		w.stats.Done++
 		info.jobNum--
+		info.done++
 		w.wg.Add(-1)
 	}
 	jobNum := info.jobNum
@@ -242,6 +268,9 @@ func (w *Workshop) hireLocked() (*workerInfo, error) {
 GET:
 	info = w.minLoadInfo
 	if len(w.infos) >= w.maxQuota || (info != nil && info.jobNum == 0) {
+		if w.balancer != nil {
+			info = w.selectLocked()
+		}
 		if !w.checkInfoLocked(info) {
 			w.refreshLocked(false)
 			goto GET
@@ -278,6 +307,28 @@ GET:
 	return info, nil
 }
 
+// selectLocked selects a worker by the balancer.
+func (w *Workshop) selectLocked() *workerInfo {
+	loads := w.loads[:0]
+	for _, info := range w.infoList {
+		loads = append(loads, WorkerLoad{
+			Worker:  info.worker,
+			Load:    info.jobNum,
+			Done:    info.done,
+			Latency: info.latency,
+		})
+	}
+	i := w.balancer.Select(loads)
+	for j := range loads {
+		loads[j].Worker = nil // avoid holding the fired workers
+	}
+	w.loads = loads
+	if i < 0 || i >= len(w.infoList) {
+		return w.minLoadInfo
+	}
+	return w.infoList[i]
+}
+
 func (w *Workshop) gc() {
 	for {
 		select {
@@ -344,6 +395,15 @@ func (w *Workshop) checkInfoLocked(info *workerInfo) bool {
 	if !info.worker.Health() ||
 		(info.jobNum == 0 && coarsetime.FloorTimeNow().After(info.idleExpire)) {
 		delete(w.infos, info.worker)
+		for i, v := range w.infoList {
+			if v == info {
+				last := len(w.infoList) - 1
+				copy(w.infoList[i:], w.infoList[i+1:])
+				w.infoList[last] = nil
+				w.infoList = w.infoList[:last]
+				break
+			}
+		}
 		info.worker.Close()
 		w.stats.Worker--
 		if info.jobNum == 0 {
@@ -358,6 +418,15 @@ func (w *Workshop) checkInfoLocked(info *workerInfo) bool {
 	return true
 }
 
+// the latency is the exponentially weighted moving average.
+func (info *workerInfo) observeLatency(d time.Duration) {
+	if info.latency == 0 {
+		info.latency = d
+		return
+	}
+	info.latency += (d - info.latency) / latencyDecay
+}
+
 func (w *Workshop) reportStatsLocked() {
 	w.statsReader.Store(*w.stats)
 }
@@ -0,0 +1,225 @@
+package status
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The field numbers of google.rpc.Status and google.protobuf.Any.
+// The cause and fields are not part of google.rpc.Status,
+// they use the large field numbers which are skipped by the protobuf decoders as unknown fields.
+const (
+	fieldCode    = 1
+	fieldMsg     = 2
+	fieldDetails = 3
+	fieldCause   = 1000
+	fieldFields  = 1001
+
+	fieldTypeURL = 1
+	fieldValue   = 2
+)
+
+// The protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var (
+	_ encoding.BinaryMarshaler   = new(Status)
+	_ encoding.BinaryUnmarshaler = new(Status)
+
+	errTruncated = errors.New("status: truncated binary data")
+)
+
+// FromBinary parses the binary bytes to a status object.
+func FromBinary(b []byte, tagStack bool) (*Status, error) {
+	s := new(Status)
+	err := s.UnmarshalBinary(b)
+	if err != nil {
+		return nil, err
+	}
+	if tagStack {
+		s.stack = callers(3)
+	}
+	return s, nil
+}
+
+// MarshalBinary marshals the status object into the protobuf wire format of google.rpc.Status,
+// implements encoding.BinaryMarshaler interface.
+// NOTE:
+//  The value of each detail is its JSON bytes, or its raw bytes if it is not JSON;
+//  The cause and fields are encoded as the unknown fields 1000 and 1001
+func (s *Status) MarshalBinary() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b := make([]byte, 0, 64+len(s.msg))
+	if s.code != OK {
+		b = appendTag(b, fieldCode, wireVarint)
+		b = appendVarint(b, uint64(int64(s.code)))
+	}
+	if len(s.msg) > 0 {
+		b = appendBytesField(b, fieldMsg, []byte(s.msg))
+	}
+	var buf []byte
+	for _, d := range s.details {
+		buf = buf[:0]
+		if len(d.TypeURL) > 0 {
+			buf = appendBytesField(buf, fieldTypeURL, []byte(d.TypeURL))
+		}
+		value := []byte(d.Value)
+		if value == nil {
+			value = d.Raw
+		}
+		if len(value) > 0 {
+			buf = appendBytesField(buf, fieldValue, value)
+		}
+		b = appendBytesField(b, fieldDetails, buf)
+	}
+	if s.cause != nil {
+		b = appendBytesField(b, fieldCause, []byte(s.cause.Error()))
+	}
+	if len(s.fields) > 0 {
+		b = appendBytesField(b, fieldFields, marshalFields(s.fields))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary unmarshals the protobuf wire format of google.rpc.Status,
+// implements encoding.BinaryUnmarshaler interface.
+// NOTE:
+//  If the value of a detail is not JSON, e.g. a protobuf message,
+//  its raw bytes are kept in Any.Raw, and encoded unchanged by MarshalBinary
+func (s *Status) UnmarshalBinary(b []byte) error {
+	if s == nil {
+		return nil
+	}
+	s.Clear()
+	for len(b) > 0 {
+		field, wire, value, n, err := consumeField(b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		switch {
+		case field == fieldCode && wire == wireVarint:
+			s.code = int32(value.varint)
+		case field == fieldMsg && wire == wireBytes:
+			s.msg = string(value.bytes)
+		case field == fieldDetails && wire == wireBytes:
+			d, err := unmarshalAny(value.bytes)
+			if err != nil {
+				return err
+			}
+			s.details = append(s.details, d)
+		case field == fieldCause && wire == wireBytes:
+			if len(value.bytes) > 0 {
+				s.cause = errors.New(string(value.bytes))
+			}
+		case field == fieldFields && wire == wireBytes:
+			var fields Map
+			if err = json.Unmarshal(value.bytes, &fields); err != nil {
+				return fmt.Errorf("status: unmarshal the fields: %v", err)
+			}
+			if len(fields) > 0 {
+				s.fields = fields
+			}
+		}
+	}
+	return nil
+}
+
+func unmarshalAny(b []byte) (Any, error) {
+	var typeURL string
+	var v []byte
+	for len(b) > 0 {
+		field, wire, value, n, err := consumeField(b)
+		if err != nil {
+			return Any{}, err
+		}
+		b = b[n:]
+		switch {
+		case field == fieldTypeURL && wire == wireBytes:
+			typeURL = string(value.bytes)
+		case field == fieldValue && wire == wireBytes:
+			v = value.bytes
+		}
+	}
+	if json.Valid(v) {
+		return Any{TypeURL: typeURL, Value: append(json.RawMessage(nil), v...)}, nil
+	}
+	if len(v) == 0 {
+		return Any{TypeURL: typeURL}, nil
+	}
+	return Any{TypeURL: typeURL, Raw: append([]byte(nil), v...)}, nil
+}
+
+type wireValue struct {
+	varint uint64
+	bytes  []byte
+}
+
+// consumeField parses a field at the beginning of b, and returns its field number,
+// wire type, value and length.
+func consumeField(b []byte) (field uint64, wire int, value wireValue, n int, err error) {
+	tag, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, 0, value, 0, errTruncated
+	}
+	field, wire = tag>>3, int(tag&7)
+	if field == 0 {
+		return 0, 0, value, 0, errors.New("status: invalid field number 0")
+	}
+	switch wire {
+	case wireVarint:
+		v, m := binary.Uvarint(b[n:])
+		if m <= 0 {
+			return 0, 0, value, 0, errTruncated
+		}
+		value.varint = v
+		n += m
+	case wireFixed64:
+		if len(b[n:]) < 8 {
+			return 0, 0, value, 0, errTruncated
+		}
+		n += 8
+	case wireFixed32:
+		if len(b[n:]) < 4 {
+			return 0, 0, value, 0, errTruncated
+		}
+		n += 4
+	case wireBytes:
+		l, m := binary.Uvarint(b[n:])
+		if m <= 0 || l > uint64(len(b[n+m:])) {
+			return 0, 0, value, 0, errTruncated
+		}
+		n += m
+		value.bytes = b[n : n+int(l)]
+		n += int(l)
+	default:
+		return 0, 0, value, 0, fmt.Errorf("status: unsupported wire type %d", wire)
+	}
+	return field, wire, value, n, nil
+}
+
+func appendTag(b []byte, field uint64, wire int) []byte {
+	return appendVarint(b, field<<3|uint64(wire))
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendBytesField(b []byte, field uint64, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,161 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestStatusBinary(t *testing.T) {
+	// google.rpc.Status{code: 5, message: "not found"}
+	b, err := New(5, "not found").MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{0x08, 0x05, 0x12, 0x09}, "not found"...); !bytes.Equal(b, want) {
+		t.Errorf("got:%x, want:%x", b, want)
+	}
+	// The negative code is sign-extended to 10 bytes.
+	b, _ = New(UnknownError, "").MarshalBinary()
+	if want := []byte{0x08, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}; !bytes.Equal(b, want) {
+		t.Errorf("got:%x, want:%x", b, want)
+	}
+	if b, _ = (*Status)(nil).MarshalBinary(); b != nil {
+		t.Errorf("expected nil bytes, got %x", b)
+	}
+
+	expect := New(400, "invalid argument", "bala...bala...").WithField("name", "andeya")
+	expect.AddDetail("type.googleapis.com/a", map[string]int{"x": 1})
+	expect.AddDetail("type.googleapis.com/b", "y")
+	b, err = expect.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := FromBinary(b, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.JSONString() != expect.JSONString() || stat.StackTrace() == nil {
+		t.Errorf("got:%s, want:%s", stat, expect)
+	}
+
+	// An unknown field is skipped.
+	b = append([]byte{0x08, 0x03}, 0x25, 1, 2, 3, 4)
+	if stat, err = FromBinary(b, false); err != nil || stat.Code() != 3 {
+		t.Errorf("unexpected status: %v, %v", stat, err)
+	}
+
+	for _, b := range [][]byte{{0x08}, {0x12, 0x05, 'a'}, {0x0b}, {0x00, 0x01}} {
+		if _, err := FromBinary(b, false); err == nil {
+			t.Errorf("expected an error unmarshaling %x", b)
+		}
+	}
+}
+
+func TestStatusBinaryRawDetails(t *testing.T) {
+	// google.rpc.Status with the repeated protobuf details of the same type URL.
+	const typeURL = "type.googleapis.com/google.rpc.ErrorInfo"
+	b := []byte{0x08, 0x03}
+	for _, value := range [][]byte{{0x0a, 0x01, 0xff}, {0x0a, 0x01, 0xfe}} {
+		buf := appendBytesField(nil, fieldTypeURL, []byte(typeURL))
+		buf = appendBytesField(buf, fieldValue, value)
+		b = appendBytesField(b, fieldDetails, buf)
+	}
+	stat, err := FromBinary(b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details := stat.Details()
+	if len(details) != 2 || details[0].TypeURL != typeURL || !bytes.Equal(details[1].Raw, []byte{0x0a, 0x01, 0xfe}) {
+		t.Fatalf("unexpected details: %+v", details)
+	}
+	if ok, err := stat.Detail(typeURL, new(string)); !ok || err == nil {
+		t.Errorf("expected an error unmarshaling the protobuf detail: %v, %v", ok, err)
+	}
+	got, err := stat.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b) {
+		t.Errorf("got:%x, want:%x", got, b)
+	}
+
+	// The raw bytes survive the JSON encoding too.
+	stat, err = FromJSON([]byte(stat.JSONString()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = stat.MarshalBinary(); !bytes.Equal(got, b) {
+		t.Errorf("got:%x, want:%x", got, b)
+	}
+}
+
+func FuzzStatusBinary(f *testing.F) {
+	f.Add([]byte(`{"code":404,"msg":"Not Found","cause":"xxxxxxxxxx"}`))
+	f.Add([]byte(`{"code":-1,"msg":"","cause":""}`))
+	f.Add([]byte(`{"code":400,"msg":"中文","cause":"c","fields":{"a":1.5,"b":[true,null]},"details":[{"type_url":"t/a","value":{"x":"y"}},{"type_url":"t/a","value":"z"},{"type_url":"t/b","raw":"CgH/"}]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		expect, err := FromJSON(data, false)
+		if err != nil {
+			return
+		}
+		want, err := expect.MarshalJSON()
+		if err != nil {
+			return
+		}
+		b, err := expect.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stat, err := FromBinary(b, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := stat.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !jsonEqual(got, want) {
+			t.Errorf("got:%s, want:%s", got, want)
+		}
+	})
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	seed, _ := New(400, "msg", "cause").WithField("k", "v").MarshalBinary()
+	f.Add(seed)
+	f.Add([]byte{0x08, 0x05, 0x12, 0x01, 'a'})
+	f.Add([]byte{0x1a, 0x05, 0x12, 0x03, 0x0a, 0x01, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		stat, err := FromBinary(data, false)
+		if err != nil {
+			return
+		}
+		want, err := stat.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := stat.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := FromBinary(b, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := again.MarshalJSON(); !jsonEqual(got, want) {
+			t.Errorf("got:%s, want:%s", got, want)
+		}
+	})
+}
+
+// jsonEqual reports whether a and b are the same JSON values.
+func jsonEqual(a, b []byte) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(a, b)
+	}
+	x, _ = json.Marshal(x)
+	y, _ = json.Marshal(y)
+	return bytes.Equal(x.([]byte), y.([]byte))
+}
@@ -0,0 +1,166 @@
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// GRPCCode is the gRPC canonical code, the same as google.golang.org/grpc/codes.Code.
+type GRPCCode uint32
+
+// The gRPC canonical codes.
+const (
+	GRPCOK                 GRPCCode = 0
+	GRPCCanceled           GRPCCode = 1
+	GRPCUnknown            GRPCCode = 2
+	GRPCInvalidArgument    GRPCCode = 3
+	GRPCDeadlineExceeded   GRPCCode = 4
+	GRPCNotFound           GRPCCode = 5
+	GRPCAlreadyExists      GRPCCode = 6
+	GRPCPermissionDenied   GRPCCode = 7
+	GRPCResourceExhausted  GRPCCode = 8
+	GRPCFailedPrecondition GRPCCode = 9
+	GRPCAborted            GRPCCode = 10
+	GRPCOutOfRange         GRPCCode = 11
+	GRPCUnimplemented      GRPCCode = 12
+	GRPCInternal           GRPCCode = 13
+	GRPCUnavailable        GRPCCode = 14
+	GRPCDataLoss           GRPCCode = 15
+	GRPCUnauthenticated    GRPCCode = 16
+)
+
+// CodeInfo is the declaration of a status code.
+type CodeInfo struct {
+	Code       int32
+	Msg        string // the default message
+	HTTPStatus int
+	GRPCCode   GRPCCode
+}
+
+var registry = struct {
+	sync.RWMutex
+	codes map[int32]CodeInfo
+}{
+	codes: map[int32]CodeInfo{
+		OK:           {Code: OK, Msg: "OK", HTTPStatus: http.StatusOK, GRPCCode: GRPCOK},
+		UnknownError: {Code: UnknownError, Msg: "unknown error", HTTPStatus: http.StatusInternalServerError, GRPCCode: GRPCUnknown},
+	},
+}
+
+// Register declares the status code with its default message, HTTP status and gRPC canonical code,
+// and returns the code.
+// NOTE:
+//  Panics if the code is already registered;
+//  OK and UnknownError are registered by default
+// Example:
+//  var CodeNotFound = status.Register(100404, "not found", http.StatusNotFound, status.GRPCNotFound)
+func Register(code int32, msg string, httpStatus int, grpcCode GRPCCode) int32 {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.codes[code]; ok {
+		panic(fmt.Sprintf("status: code %d is already registered", code))
+	}
+	registry.codes[code] = CodeInfo{Code: code, Msg: msg, HTTPStatus: httpStatus, GRPCCode: grpcCode}
+	return code
+}
+
+// Lookup returns the declaration of the status code.
+func Lookup(code int32) (CodeInfo, bool) {
+	registry.RLock()
+	info, ok := registry.codes[code]
+	registry.RUnlock()
+	return info, ok
+}
+
+// Codes returns the declarations of all the registered codes, sorted by code.
+func Codes() []CodeInfo {
+	registry.RLock()
+	infos := make([]CodeInfo, 0, len(registry.codes))
+	for _, info := range registry.codes {
+		infos = append(infos, info)
+	}
+	registry.RUnlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
+
+// FromCode creates a handling status with the registered code, its default msg and cause.
+// NOTE:
+//  If the code is not registered, msg is empty
+func FromCode(code int32, cause ...interface{}) *Status {
+	info, _ := Lookup(code)
+	return New(code, info.Msg, cause...)
+}
+
+// CodeFromHTTP returns the smallest registered code of the HTTP status.
+// NOTE:
+//  If no code is registered, returns OK for 2xx, otherwise UnknownError
+func CodeFromHTTP(httpStatus int) int32 {
+	for _, info := range Codes() {
+		if info.HTTPStatus == httpStatus && info.Code != UnknownError {
+			return info.Code
+		}
+	}
+	if httpStatus >= 200 && httpStatus < 300 {
+		return OK
+	}
+	return UnknownError
+}
+
+// HTTPStatus returns the HTTP status of the status code.
+// NOTE:
+//  If the code is not registered, returns 500
+func (s *Status) HTTPStatus() int {
+	if info, ok := Lookup(s.Code()); ok {
+		return info.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the gRPC canonical code of the status code.
+// NOTE:
+//  If the code is not registered, returns GRPCUnknown
+func (s *Status) GRPCCode() GRPCCode {
+	if info, ok := Lookup(s.Code()); ok {
+		return info.GRPCCode
+	}
+	return GRPCUnknown
+}
+
+// WriteHTTP renders the status into the HTTP response,
+// with its HTTP status and JSON body.
+func WriteHTTP(w http.ResponseWriter, s *Status) error {
+	b, _ := s.MarshalJSON()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(s.HTTPStatus())
+	_, err := w.Write(b)
+	return err
+}
+
+// FromHTTPResponse reads the status back from the HTTP response written by WriteHTTP.
+// NOTE:
+//  The body is read but not closed;
+//  If the body is not a status, the status is created by the HTTP status,
+//  and the body text is the cause
+func FromHTTPResponse(resp *http.Response, tagStack bool) (*Status, error) {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Status)
+	if len(bytes.TrimSpace(b)) == 0 || s.UnmarshalJSON(b) != nil || s.OK() && resp.StatusCode >= 400 {
+		code := CodeFromHTTP(resp.StatusCode)
+		s = FromCode(code)
+		if len(b) > 0 && code != OK {
+			s.cause = toErr(string(b))
+		}
+	}
+	if tagStack {
+		s.stack = callers(3)
+	}
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package status
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testCodeNotFound = Register(100404, "not found", http.StatusNotFound, GRPCNotFound)
+
+func TestRegister(t *testing.T) {
+	info, ok := Lookup(testCodeNotFound)
+	if !ok || info.Msg != "not found" || info.HTTPStatus != http.StatusNotFound || info.GRPCCode != GRPCNotFound {
+		t.Fatalf("unexpected code info: %+v", info)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic registering the duplicate code")
+			}
+		}()
+		Register(testCodeNotFound, "", http.StatusNotFound, GRPCNotFound)
+	}()
+
+	s := FromCode(testCodeNotFound, "no such user")
+	if s.Msg() != "not found" || s.HTTPStatus() != http.StatusNotFound || s.GRPCCode() != GRPCNotFound {
+		t.Errorf("unexpected status: %v", s)
+	}
+	if s := New(123456789, "unregistered"); s.HTTPStatus() != http.StatusInternalServerError || s.GRPCCode() != GRPCUnknown {
+		t.Errorf("unexpected mapping of the unregistered code: %d, %d", s.HTTPStatus(), s.GRPCCode())
+	}
+	if (*Status)(nil).HTTPStatus() != http.StatusOK || (*Status)(nil).GRPCCode() != GRPCOK {
+		t.Error("expected nil status is OK")
+	}
+	if CodeFromHTTP(http.StatusNotFound) != testCodeNotFound || CodeFromHTTP(http.StatusNoContent) != OK || CodeFromHTTP(http.StatusBadGateway) != UnknownError {
+		t.Error("unexpected code from HTTP status")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	expect := FromCode(testCodeNotFound, "no such user")
+	w := httptest.NewRecorder()
+	if err := WriteHTTP(w, expect); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if resp.StatusCode != http.StatusNotFound || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	s, err := FromHTTPResponse(resp, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Code() != expect.Code() || s.Msg() != expect.Msg() || s.Cause().Error() != "no such user" {
+		t.Errorf("got:%s, want:%s", s, expect)
+	}
+
+	// The body is not a status.
+	w = httptest.NewRecorder()
+	http.Error(w, "page not found", http.StatusNotFound)
+	s, err = FromHTTPResponse(w.Result(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Code() != testCodeNotFound || !strings.Contains(s.Cause().Error(), "page not found") || s.StackTrace() == nil {
+		t.Errorf("unexpected status: %+v", s)
+	}
+}
@@ -58,11 +58,13 @@ func CatchWithStack(statPtr **Status, realStat ...*bool) {
 		if v == nil {
 			*statPtr = &Status{stack: stack}
 		} else {
-			*statPtr = &Status{code: v.code, msg: v.msg, cause: v.cause, stack: stack}
+			*statPtr = v.clone(v.cause)
+			(*statPtr).stack = stack
 		}
 	case Status:
 		trySetBool(realStat, true)
-		*statPtr = &Status{code: v.code, msg: v.msg, cause: v.cause, stack: stack}
+		*statPtr = v.clone(v.cause)
+		(*statPtr).stack = stack
 	default:
 		trySetBool(realStat, false)
 		*statPtr = &Status{code: UnknownError, cause: toErr(v), stack: stack}
@@ -105,7 +107,7 @@ func Catch(statPtr **Status, realStat ...*bool) {
 		*statPtr = v
 	case Status:
 		trySetBool(realStat, true)
-		*statPtr = &v
+		*statPtr = v.clone(v.cause)
 	default:
 		trySetBool(realStat, false)
 		*statPtr = New(UnknownError, "", v).TagStack(2)
@@ -210,3 +210,16 @@ func TestGetStackTrace(t *testing.T) {
 		t.Logf("%+v", stack)
 	}()
 }
+
+func TestCatchStatusValue(t *testing.T) {
+	expect := New(400, "invalid argument").WithField("name", "andeya")
+	var stat *Status
+	func() {
+		defer Catch(&stat)
+		panic(*expect)
+	}()
+	stat.WithField("name", "other")
+	if expect.Fields()["name"] != "andeya" || stat.Fields()["name"] != "other" || stat.Code() != 400 {
+		t.Errorf("expected the caught status does not share the fields, got %v and %v", expect, stat)
+	}
+}
@@ -0,0 +1,145 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithField sets the field to the status object, e.g. the invalid argument of a validation error.
+// NOTE:
+//  If the value can not be marshaled into JSON, e.g. a channel or NaN, its string form "%v" is set instead;
+//  After FromJSON or FromQuery, the value is of the type decoded by encoding/json, e.g. float64 for numbers
+func (s *Status) WithField(key string, value interface{}) *Status {
+	if s != nil {
+		if s.fields == nil {
+			s.fields = make(Map)
+		}
+		s.fields[key] = jsonFieldValue(value)
+	}
+	return s
+}
+
+// jsonFieldValue returns the value if it can be marshaled into JSON, or else its string form.
+func jsonFieldValue(value interface{}) interface{} {
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return value
+}
+
+// marshalFields marshals the fields into JSON,
+// the values which can not be marshaled any more, e.g. modified after WithField, are replaced by their string forms.
+func marshalFields(m Map) []byte {
+	b, err := json.Marshal(m)
+	if err == nil {
+		return b
+	}
+	c := make(Map, len(m))
+	for k, v := range m {
+		c[k] = jsonFieldValue(v)
+	}
+	b, _ = json.Marshal(c)
+	return b
+}
+
+// Fields returns the copy of the fields of the status object.
+func (s *Status) Fields() Map {
+	if s == nil || len(s.fields) == 0 {
+		return nil
+	}
+	return copyMap(s.fields)
+}
+
+// Any is a detail of the status object, similar to google.protobuf.Any.
+type Any struct {
+	TypeURL string `json:"type_url"`
+	// Value is the JSON value of the detail.
+	Value json.RawMessage `json:"value,omitempty"`
+	// Raw is the value which is not JSON, e.g. a protobuf message decoded by UnmarshalBinary,
+	// it is encoded unchanged by MarshalBinary.
+	Raw []byte `json:"raw,omitempty"`
+}
+
+// AddDetail marshals the value into JSON and appends it as the detail of the type URL,
+// similar to the repeated details of google.rpc.Status.
+// NOTE:
+//  If value is json.RawMessage, it is appended directly after validated;
+//  The details of the same type URL are all kept in order
+// Example:
+//  s.AddDetail("type.googleapis.com/google.rpc.BadRequest", badRequest)
+func (s *Status) AddDetail(typeURL string, value interface{}) error {
+	if s == nil {
+		return nil
+	}
+	raw, ok := value.(json.RawMessage)
+	if ok {
+		if !json.Valid(raw) {
+			return fmt.Errorf("status: the detail %s is invalid JSON", typeURL)
+		}
+		raw = append(json.RawMessage(nil), raw...)
+	} else {
+		var err error
+		raw, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("status: marshal the detail %s: %v", typeURL, err)
+		}
+	}
+	s.details = append(s.details, Any{TypeURL: typeURL, Value: raw})
+	return nil
+}
+
+// Detail unmarshals the first detail of the type URL into value, and reports whether the detail exists.
+// NOTE:
+//  If the detail is not JSON, an error is returned, use Details to get its raw bytes
+func (s *Status) Detail(typeURL string, value interface{}) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	for _, d := range s.details {
+		if d.TypeURL != typeURL {
+			continue
+		}
+		if d.Value == nil {
+			return true, fmt.Errorf("status: the detail %s is not JSON", typeURL)
+		}
+		return true, json.Unmarshal(d.Value, value)
+	}
+	return false, nil
+}
+
+// Details returns the copy of the details of the status object in order.
+func (s *Status) Details() []Any {
+	if s == nil || len(s.details) == 0 {
+		return nil
+	}
+	return copyDetails(s.details)
+}
+
+// checkDetails drops the raw bytes of the decoded details which have the JSON value,
+// so that the details are encoded the same way by MarshalJSON and MarshalBinary.
+func checkDetails(a []Any) []Any {
+	for i := range a {
+		if a[i].Value != nil {
+			a[i].Raw = nil
+		}
+	}
+	return a
+}
+
+func copyMap(m Map) Map {
+	if m == nil {
+		return nil
+	}
+	c := make(Map, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func copyDetails(a []Any) []Any {
+	if a == nil {
+		return nil
+	}
+	return append([]Any(nil), a...)
+}
@@ -0,0 +1,99 @@
+package status
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andeya/goutil/errors"
+)
+
+// Go runs fn in a new goroutine, and calls handler with the result if it is not OK.
+// NOTE:
+//  The panic of fn, including the one raised by Throw, Check or Panic, is recovered as the result with the panic stack;
+//  The panic of a non-Status value is recovered as an UnknownError status, whose cause is the value;
+//  handler may be nil, and its panic is not recovered
+func Go(fn func() *Status, handler func(*Status)) {
+	go func() {
+		stat := call(fn)
+		if !stat.OK() && handler != nil {
+			handler(stat)
+		}
+	}()
+}
+
+// call runs fn, and recovers its panic as the result.
+func call(fn func() *Status) (stat *Status) {
+	defer func() {
+		if r := recover(); r != nil {
+			stat = fromPanic(r)
+		}
+	}()
+	return fn()
+}
+
+// fromPanic converts the recovered value to a status with the panic stack.
+// NOTE:
+//  It must be called in the deferred function during panicking
+func fromPanic(r interface{}) *Status {
+	stack := findPanicStack()
+	var stat *Status
+	switch v := r.(type) {
+	case *Status:
+		if v == nil {
+			return &Status{stack: stack}
+		}
+		stat = v.clone(v.cause)
+	case Status:
+		stat = v.clone(v.cause)
+	default:
+		stat = New(UnknownError, "", v)
+	}
+	stat.stack = stack
+	return stat
+}
+
+// Group is a collection of goroutines returning status, like errgroup.
+// A zero Group is valid and does not cancel on failure.
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	lock   sync.Mutex
+	errs   []error
+}
+
+// NewGroup creates a goroutine group, and returns a derived context which is canceled
+// the first time a goroutine returns a status that is not OK, or the first time Wait returns.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go runs fn in a new goroutine.
+// NOTE:
+//  The panic of fn is recovered as its result, the same as the function Go
+func (g *Group) Go(fn func() *Status) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if stat := call(fn); !stat.OK() {
+			g.lock.Lock()
+			g.errs = append(g.errs, stat)
+			g.lock.Unlock()
+			if g.cancel != nil {
+				g.cancel()
+			}
+		}
+	}()
+}
+
+// Wait blocks until all the goroutines have completed,
+// then returns all their statuses that are not OK merged by errors.Merge, or nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return errors.Merge(g.errs...)
+}
@@ -0,0 +1,82 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	goutilerrors "github.com/andeya/goutil/errors"
+)
+
+func TestGo(t *testing.T) {
+	results := make(chan *Status, 1)
+	handler := func(stat *Status) { results <- stat }
+
+	Go(func() *Status { return New(400, "bad request") }, handler)
+	if stat := <-results; stat.Code() != 400 {
+		t.Errorf("unexpected status: %v", stat)
+	}
+
+	Go(func() *Status {
+		Throw(404, "not found", io.EOF)
+		return nil
+	}, handler)
+	stat := <-results
+	if stat.Code() != 404 || !errors.Is(stat, io.EOF) || len(stat.StackTrace()) == 0 {
+		t.Errorf("unexpected status: %+v", stat)
+	}
+	if !strings.Contains(stat.StackTrace().Compact(), "TestGo.func") {
+		t.Errorf("expected the panic stack, got %s", stat.StackTrace().Compact())
+	}
+
+	Go(func() *Status {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	}, handler)
+	if stat := <-results; !stat.UnknownError() || !strings.Contains(stat.Msg(), "nil map") {
+		t.Errorf("unexpected status: %v", stat)
+	}
+
+	// The OK result is not handled.
+	Go(func() *Status { return nil }, handler)
+	Go(func() *Status { return New(OK, "") }, nil)
+	select {
+	case stat := <-results:
+		t.Errorf("unexpected status: %v", stat)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g, ctx := NewGroup(context.Background())
+	g.Go(func() *Status { return nil })
+	g.Go(func() *Status {
+		Panic(New(500, "panic", io.ErrUnexpectedEOF))
+		return nil
+	})
+	g.Go(func() *Status {
+		<-ctx.Done()
+		return New(499, "canceled", ctx.Err())
+	})
+	err := g.Wait()
+	if errs := goutilerrors.Errors(err); len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.Is(err, New(499, "")) || !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var stat *Status
+	if !errors.As(err, &stat) || len(stat.StackTrace()) == 0 {
+		t.Errorf("expected the status with stack, got %+v", stat)
+	}
+
+	var zero Group
+	zero.Go(func() *Status { return nil })
+	if err := zero.Wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
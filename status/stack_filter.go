@@ -0,0 +1,246 @@
+package status
+
+import (
+	"fmt"
+	"go/build"
+	"hash/fnv"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// FramePredicate reports whether the frame should be kept by StackTrace.Filter.
+type FramePredicate func(Frame) bool
+
+// Filter returns the frames which satisfy all the predicates.
+// Example:
+//  st.Filter(status.NotRuntime, status.NotStdlib, status.NotVendored)
+func (st StackTrace) Filter(predicates ...FramePredicate) StackTrace {
+	filtered := make(StackTrace, 0, len(st))
+L:
+	for _, f := range st {
+		for _, p := range predicates {
+			if p != nil && !p(f) {
+				continue L
+			}
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
+
+// NotRuntime reports whether the frame is not in the runtime packages, e.g. runtime.gopanic.
+func NotRuntime(f Frame) bool {
+	pkg := f.pkg()
+	return pkg != "runtime" && !strings.HasPrefix(pkg, "runtime/")
+}
+
+// NotStdlib reports whether the frame is not in the standard library, including the runtime.
+// NOTE:
+//  The package whose import path does not contain a dot in the first element is regarded as the standard library,
+//  except the main package
+func NotStdlib(f Frame) bool {
+	pkg := f.pkg()
+	if pkg == "main" || pkg == "unknown" {
+		return true
+	}
+	first := pkg
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		first = pkg[:i]
+	}
+	return strings.Contains(first, ".")
+}
+
+// NotVendored reports whether the frame is not in the vendor directory.
+func NotVendored(f Frame) bool {
+	return !strings.Contains(f.name(), "/vendor/") && !strings.Contains(filepath.ToSlash(f.file()), "/vendor/")
+}
+
+// Func returns the full name of the function of the frame, e.g. github.com/andeya/goutil/status.New.
+func (f Frame) Func() string {
+	return f.name()
+}
+
+// File returns the full path of the source file of the frame.
+func (f Frame) File() string {
+	return f.file()
+}
+
+// Line returns the line number of the source file of the frame.
+func (f Frame) Line() int {
+	return f.line()
+}
+
+// TrimmedFile returns the path of the source file relative to the GOPATH or the module root,
+// which is independent of the build machine, e.g.
+//
+//    /home/me/go/pkg/mod/github.com/andeya/goutil@v1.0.0/status/status.go
+//    => github.com/andeya/goutil/status/status.go
+//    /usr/local/go/src/net/http/server.go
+//    => net/http/server.go
+// NOTE:
+//  The module versions, e.g. "@v2.4.0", are removed, so the path is stable across the dependency upgrades
+func (f Frame) TrimmedFile() string {
+	return trimFile(f.name(), f.file())
+}
+
+// shortFunc returns the function name without the package path prefix, e.g. status.New.
+func (f Frame) shortFunc() string {
+	name := unescapeFunc(f.name())
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// pkg returns the import path of the package of the function.
+func (f Frame) pkg() string {
+	return funcPackage(f.name())
+}
+
+// funcPackage returns the import path of the package of the function name, e.g.
+//
+//    gopkg.in/yaml.v2.(*decoder).unmarshal
+//    => gopkg.in/yaml.v2
+// NOTE:
+//  The dots in the last element of the import path may be escaped as "%2e" in the function name
+func funcPackage(name string) string {
+	i := strings.LastIndex(name, "/")
+	rest := name[i+1:]
+	j := strings.IndexByte(rest, '.')
+	// Skip the major version suffixes of the last element, e.g. ".v2" of yaml.v2
+	for j >= 0 && isMajorVersion(rest[j+1:]) {
+		k := strings.IndexByte(rest[j+1:], '.')
+		if k < 0 {
+			break
+		}
+		j += 1 + k
+	}
+	if j < 0 {
+		return unescapeFunc(name)
+	}
+	return unescapeFunc(name[:i+1+j])
+}
+
+// isMajorVersion reports whether s begins with the major version followed by a dot, e.g. "v2.".
+func isMajorVersion(s string) bool {
+	if len(s) < 3 || s[0] != 'v' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.':
+			return i > 1
+		case c < '0' || c > '9':
+			return false
+		}
+	}
+	return false
+}
+
+func unescapeFunc(name string) string {
+	return strings.ReplaceAll(name, "%2e", ".")
+}
+
+// trimFile returns the path of the source file relative to the GOPATH or the module root,
+// without the module versions.
+func trimFile(name, file string) string {
+	file = filepath.ToSlash(file)
+	if file == "unknown" {
+		return file
+	}
+	dir, base := path.Split(file)
+	dir = strings.TrimSuffix(dir, "/")
+	pkg := funcPackage(name)
+	if pkg != "main" && pkg != "unknown" {
+		// The directory ends with at least the last element of the package import path,
+		// which is followed by the module version if it is the module root, e.g. yaml.v2@v2.4.0.
+		last := pkg[strings.LastIndex(pkg, "/")+1:]
+		if trimVersion(path.Base(dir)) == last {
+			return pkg + "/" + base
+		}
+	}
+	for _, prefix := range trimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return trimVersion(file[len(prefix):])
+		}
+	}
+	return trimVersion(path.Base(dir)) + "/" + base
+}
+
+// trimVersion removes the module versions from the path, e.g.
+//  github.com/andeya/goutil@v1.0.0/status => github.com/andeya/goutil/status
+func trimVersion(p string) string {
+	for {
+		i := strings.IndexByte(p, '@')
+		if i < 0 {
+			return p
+		}
+		j := strings.IndexByte(p[i:], '/')
+		if j < 0 {
+			return p[:i]
+		}
+		p = p[:i] + p[i+j:]
+	}
+}
+
+// trimPrefixes are the GOROOT and GOPATH source directories trimmed by TrimmedFile.
+var trimPrefixes = func() []string {
+	var prefixes []string
+	if goroot := runtime.GOROOT(); goroot != "" {
+		prefixes = append(prefixes, filepath.ToSlash(goroot)+"/src/")
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		gopath = filepath.ToSlash(gopath)
+		prefixes = append(prefixes, gopath+"/pkg/mod/", gopath+"/src/")
+	}
+	return prefixes
+}()
+
+// Compact returns the one-line format of the stack trace, e.g.
+//  status.TestCompact(github.com/andeya/goutil/status/stack_test.go:12) < testing.tRunner(testing/testing.go:1439)
+func (st StackTrace) Compact() string {
+	var b strings.Builder
+	for i, f := range st {
+		if i > 0 {
+			b.WriteString(" < ")
+		}
+		b.WriteString(f.shortFunc())
+		b.WriteByte('(')
+		b.WriteString(f.TrimmedFile())
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.line()))
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// Fingerprint returns the stable hash of the stack trace, which can be used to group the identical panics.
+// NOTE:
+//  The function names, the trimmed file paths and the line numbers are hashed,
+//  so the fingerprint survives the builds on different machines and the dependency upgrades;
+//  Filter the stack trace first to ignore the irrelevant frames
+func (st StackTrace) Fingerprint() string {
+	return st.fingerprint(true)
+}
+
+// FuncFingerprint is like Fingerprint, but the line numbers are not hashed,
+// so it survives the unrelated code changes, but the different panics in the same functions collide.
+func (st StackTrace) FuncFingerprint() string {
+	return st.fingerprint(false)
+}
+
+func (st StackTrace) fingerprint(withLine bool) string {
+	h := fnv.New64a()
+	for _, f := range st {
+		name := f.name()
+		h.Write([]byte(unescapeFunc(name)))
+		h.Write([]byte{0})
+		h.Write([]byte(trimFile(name, f.file())))
+		h.Write([]byte{0})
+		if withLine {
+			h.Write([]byte(strconv.Itoa(f.line())))
+			h.Write([]byte{0})
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
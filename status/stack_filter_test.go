@@ -0,0 +1,128 @@
+package status
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStackTraceFilter(t *testing.T) {
+	var st StackTrace
+	func() {
+		defer func() {
+			recover()
+			st = PanicStackTrace()
+		}()
+		panic("test")
+	}()
+	if len(st) == 0 {
+		t.Fatal("expected the panic stack trace")
+	}
+	filtered := st.Filter(NotRuntime, NotStdlib, NotVendored)
+	if len(filtered) == 0 || len(filtered) >= len(st) {
+		t.Fatalf("unexpected filtered stack trace: %v", filtered)
+	}
+	for _, f := range filtered {
+		if !strings.HasPrefix(f.Func(), "github.com/andeya/goutil/status.") {
+			t.Errorf("unexpected frame: %s", f.Func())
+		}
+	}
+	if !strings.HasSuffix(filtered[0].File(), "stack_filter_test.go") || filtered[0].Line() == 0 {
+		t.Errorf("unexpected frame: %s:%d", filtered[0].File(), filtered[0].Line())
+	}
+	if got := len(st.Filter()); got != len(st) {
+		t.Errorf("expected no frames filtered, got %d of %d", got, len(st))
+	}
+	var runtimeFrames int
+	for _, f := range st {
+		if !NotRuntime(f) {
+			runtimeFrames++
+			if NotStdlib(f) {
+				t.Errorf("expected the runtime frame is stdlib: %s", f.Func())
+			}
+		}
+	}
+	if len(st.Filter(NotRuntime)) != len(st)-runtimeFrames {
+		t.Error("unexpected runtime frames filtered")
+	}
+}
+
+func TestStackTraceCompact(t *testing.T) {
+	st := GetStackTrace(0).Filter(NotRuntime)
+	if got := st[0].TrimmedFile(); got != "github.com/andeya/goutil/status/stack_filter_test.go" {
+		t.Errorf("unexpected trimmed file: %s", got)
+	}
+	compact := st.Compact()
+	if strings.Contains(compact, "\n") || !strings.HasPrefix(compact, "status.TestStackTraceCompact(github.com/andeya/goutil/status/stack_filter_test.go:") {
+		t.Errorf("unexpected compact format: %s", compact)
+	}
+	if len(st) > 1 && !strings.Contains(compact, " < testing.tRunner(testing/testing.go:") {
+		t.Errorf("unexpected compact format: %s", compact)
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	for name, want := range map[string]string{
+		"github.com/andeya/goutil/status.New":                 "github.com/andeya/goutil/status",
+		"github.com/andeya/goutil/status.(*Status).Error":     "github.com/andeya/goutil/status",
+		"gopkg.in/yaml.v2.(*decoder).unmarshal":               "gopkg.in/yaml.v2",
+		"gopkg.in/yaml%2ev2.(*decoder).unmarshal":             "gopkg.in/yaml.v2",
+		"gopkg.in/yaml.v3.Unmarshal":                          "gopkg.in/yaml.v3",
+		"github.com/foo/bar/v2.Open.func1":                    "github.com/foo/bar/v2",
+		"runtime.gopanic":                                     "runtime",
+		"main.main":                                           "main",
+		"unknown":                                             "unknown",
+		"github.com/foo/bar.v10x.Method":                      "github.com/foo/bar",
+		"github.com/foo/vendor/gopkg.in/check.v1.(*C).Fatalf": "github.com/foo/vendor/gopkg.in/check.v1",
+	} {
+		if got := funcPackage(name); got != want {
+			t.Errorf("funcPackage(%q) got:%s, want:%s", name, got, want)
+		}
+	}
+}
+
+func TestTrimFile(t *testing.T) {
+	for _, c := range []struct{ name, file, want string }{
+		{"gopkg.in/yaml.v2.(*decoder).unmarshal", "/home/me/go/pkg/mod/gopkg.in/yaml.v2@v2.4.0/decode.go", "gopkg.in/yaml.v2/decode.go"},
+		{"github.com/foo/bar/v2.Open", "/home/me/go/pkg/mod/github.com/foo/bar/v2@v2.1.0/open.go", "github.com/foo/bar/v2/open.go"},
+		{"github.com/foo/bar/v2/baz.Do", "/home/me/go/pkg/mod/github.com/foo/bar/v2@v2.1.0/baz/do.go", "github.com/foo/bar/v2/baz/do.go"},
+		{"github.com/foo/bar.Open", "/home/me/go/pkg/mod/github.com/foo/bar@v1.0.0/open.go", "github.com/foo/bar/open.go"},
+		{"main.main", "/home/me/app/main.go", "app/main.go"},
+		{"unknown", "unknown", "unknown"},
+	} {
+		if got := trimFile(c.name, c.file); got != c.want {
+			t.Errorf("trimFile(%q, %q) got:%s, want:%s", c.name, c.file, got, c.want)
+		}
+	}
+	if got := trimVersion("a@v1.0.0/b@v2.0.0-pre/c.go"); got != "a/b/c.go" {
+		t.Errorf("unexpected trimmed version: %s", got)
+	}
+}
+
+func TestStackTraceFingerprint(t *testing.T) {
+	var fingerprints, funcFingerprints []string
+	for i := 0; i < 2; i++ {
+		// The same function on the different lines.
+		var st StackTrace
+		if i == 0 {
+			st = GetStackTrace(0)
+		} else {
+			st = GetStackTrace(0)
+		}
+		fingerprints = append(fingerprints, st.Fingerprint())
+		funcFingerprints = append(funcFingerprints, st.FuncFingerprint())
+	}
+	if fingerprints[0] == fingerprints[1] || len(fingerprints[0]) != 16 {
+		t.Errorf("expected the different fingerprints of the different lines, got %v", fingerprints)
+	}
+	if funcFingerprints[0] != funcFingerprints[1] || len(funcFingerprints[0]) != 16 {
+		t.Errorf("expected the same function fingerprint, got %v", funcFingerprints)
+	}
+	st := GetStackTrace(0)
+	if st.Fingerprint() != st.Fingerprint() {
+		t.Error("expected the stable fingerprint")
+	}
+	other := func() string { return GetStackTrace(0).FuncFingerprint() }()
+	if other == funcFingerprints[0] {
+		t.Error("expected the different fingerprint of the different stack")
+	}
+}
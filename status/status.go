@@ -22,9 +22,11 @@ const (
 
 // Status a handling status with code, msg, cause and stack.
 type Status struct {
-	code  int32
-	msg   string
-	cause error
+	code    int32
+	msg     string
+	cause   error
+	fields  Map
+	details []Any
 	*stack
 }
 
@@ -90,12 +92,12 @@ func (s *Status) NewCheck(err error, whenError ...func()) {
 	if len(whenError) > 0 && whenError[0] != nil {
 		whenError[0]()
 	}
-	panic(New(s.code, s.msg, err).TagStack(1))
+	panic(s.clone(err).TagStack(1))
 }
 
 // NewThrow copies the status with stack, and panic.
 func (s *Status) NewThrow(cause ...interface{}) {
-	ns := New(s.code, s.msg, s.cause).TagStack(1)
+	ns := s.clone(s.cause).TagStack(1)
 	if len(cause) > 0 {
 		ns.cause = toErr(cause[0])
 	}
@@ -110,13 +112,21 @@ func (s *Status) Copy(newCause interface{}, newStackSkip ...int) *Status {
 	if newCause == nil {
 		newCause = s.cause
 	}
-	copy := New(s.code, s.msg, newCause)
+	copy := s.clone(newCause)
 	if len(newStackSkip) != 0 {
 		copy.stack = callers(3 + newStackSkip[0])
 	}
 	return copy
 }
 
+// clone returns the copy of Status with the new cause, without stack.
+func (s *Status) clone(cause interface{}) *Status {
+	c := New(s.code, s.msg, cause)
+	c.fields = copyMap(s.fields)
+	c.details = copyDetails(s.details)
+	return c
+}
+
 // SetCode sets a new code to the status object.
 func (s *Status) SetCode(code int32) *Status {
 	if s != nil {
@@ -191,6 +201,34 @@ func (s *Status) UnknownError() bool {
 	return s.Code() == UnknownError
 }
 
+// Error implements the error interface, returns the same as String.
+func (s *Status) Error() string {
+	return s.String()
+}
+
+// Unwrap returns the cause of the status, for errors.Is and errors.As.
+func (s *Status) Unwrap() error {
+	if s == nil {
+		return nil
+	}
+	return s.cause
+}
+
+// Is reports whether the target is a *Status with the same code, for errors.Is.
+func (s *Status) Is(target error) bool {
+	t, ok := target.(*Status)
+	return ok && t.Code() == s.Code()
+}
+
+// As sets the target to the status if it is a **Status, for errors.As.
+func (s *Status) As(target interface{}) bool {
+	t, ok := target.(**Status)
+	if ok {
+		*t = s
+	}
+	return ok
+}
+
 // StackTrace returns stack trace.
 func (s *Status) StackTrace() StackTrace {
 	if s == nil || s.stack == nil {
@@ -249,23 +287,30 @@ func (s *Status) Format(state fmt.State, verb rune) {
 }
 
 type exportStatus struct {
-	Code  int32  `json:"code"`
-	Msg   string `json:"msg"`
-	Cause string `json:"cause"`
+	Code    int32  `json:"code"`
+	Msg     string `json:"msg"`
+	Cause   string `json:"cause"`
+	Fields  Map    `json:"fields,omitempty"`
+	Details []Any  `json:"details,omitempty"`
 }
 
 var (
 	reA  = []byte(`{"code":`)
 	reB  = []byte(`,"msg":`)
 	reC  = []byte(`,"cause":`)
+	reD  = []byte(`,"fields":`)
+	reE  = []byte(`,"details":`)
 	null = []byte("null")
 )
 var (
+	_ error            = new(Status)
 	_ json.Marshaler   = new(Status)
 	_ json.Unmarshaler = new(Status)
 )
 
 // MarshalJSON marshals the status object into JSON, implements json.Marshaler interface.
+// NOTE:
+//  The fields which can not be marshaled are encoded as their string forms, the same as EncodeQuery
 func (s *Status) MarshalJSON() ([]byte, error) {
 	if s == nil {
 		return null, nil
@@ -282,6 +327,17 @@ func (s *Status) MarshalJSON() ([]byte, error) {
 	b = append(b, reC...)
 	b = append(b, goutil.StringMarshalJSON(cause, false)...)
 
+	if len(s.fields) > 0 {
+		b = append(b, reD...)
+		b = append(b, marshalFields(s.fields)...)
+	}
+	if len(s.details) > 0 {
+		if details, err := json.Marshal(s.details); err == nil {
+			b = append(b, reE...)
+			b = append(b, details...)
+		}
+	}
+
 	b = append(b, '}')
 	return b, nil
 }
@@ -307,16 +363,22 @@ func (s *Status) UnmarshalJSON(b []byte) error {
 	} else {
 		s.cause = nil
 	}
+	s.fields = v.Fields
+	s.details = checkDetails(v.Details)
 	return nil
 }
 
 var (
-	keyCode  = []byte("code")
-	keyMsg   = []byte("msg")
-	keyCause = []byte("cause")
+	keyCode    = []byte("code")
+	keyMsg     = []byte("msg")
+	keyCause   = []byte("cause")
+	keyFields  = []byte("fields")
+	keyDetails = []byte("details")
 )
 
 // EncodeQuery encodes the status object into query bytes.
+// NOTE:
+//  The fields and details are encoded as JSON, the same as MarshalJSON
 func (s *Status) EncodeQuery() []byte {
 	if s == nil {
 		return nil
@@ -337,6 +399,20 @@ func (s *Status) EncodeQuery() []byte {
 		b = append(b, '=')
 		b = appendQuotedArg(b, goutil.StringToBytes(s.cause.Error()))
 	}
+	if len(s.fields) > 0 {
+		b = append(b, '&')
+		b = append(b, keyFields...)
+		b = append(b, '=')
+		b = appendQuotedArg(b, marshalFields(s.fields))
+	}
+	if len(s.details) > 0 {
+		if details, err := json.Marshal(s.details); err == nil {
+			b = append(b, '&')
+			b = append(b, keyDetails...)
+			b = append(b, '=')
+			b = appendQuotedArg(b, details)
+		}
+	}
 	return b
 }
 
@@ -349,7 +425,7 @@ func (s *Status) DecodeQuery(b []byte) {
 	if len(b) == 0 {
 		return
 	}
-	var hadCode, hadMsg, hadCause bool
+	var hadCode, hadMsg, hadCause, hadFields, hadDetails bool
 	kv := &argsKV{
 		key:   make([]byte, 0, 8),
 		value: make([]byte, 0, 128),
@@ -359,24 +435,28 @@ func (s *Status) DecodeQuery(b []byte) {
 		if !hadCode && bytes.Equal(keyCode, kv.key) {
 			i, _ := strconv.ParseInt(goutil.BytesToString(kv.value), 10, 32)
 			s.code = int32(i)
-			if hadMsg && hadCause {
-				return
-			}
 			hadCode = true
 		} else if !hadMsg && bytes.Equal(keyMsg, kv.key) {
-			if hadCode && hadCause {
-				s.msg = goutil.BytesToString(kv.value)
-				return
-			}
 			s.msg = string(kv.value)
 			hadMsg = true
 		} else if !hadCause && bytes.Equal(keyCause, kv.key) {
-			if hadCode && hadMsg {
-				s.cause = errors.New(goutil.BytesToString(kv.value))
-				return
-			}
 			s.cause = errors.New(string(kv.value))
 			hadCause = true
+		} else if !hadFields && bytes.Equal(keyFields, kv.key) {
+			var fields Map
+			if json.Unmarshal(kv.value, &fields) == nil && len(fields) > 0 {
+				s.fields = fields
+			}
+			hadFields = true
+		} else if !hadDetails && bytes.Equal(keyDetails, kv.key) {
+			var details []Any
+			if json.Unmarshal(kv.value, &details) == nil && len(details) > 0 {
+				s.details = checkDetails(details)
+			}
+			hadDetails = true
+		}
+		if hadCode && hadMsg && hadCause && hadFields && hadDetails {
+			return
 		}
 	}
 }
@@ -385,14 +465,14 @@ func toErr(cause interface{}) error {
 	switch v := cause.(type) {
 	case nil:
 		return nil
-	case error:
-		return v
-	case string:
-		return errors.New(v)
 	case *Status:
 		return v.cause
 	case Status:
 		return v.cause
+	case error:
+		return v
+	case string:
+		return errors.New(v)
 	default:
 		return fmt.Errorf("%v", v)
 	}
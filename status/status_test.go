@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -114,6 +117,105 @@ func TestStatusPanic4(t *testing.T) {
 	panic("this is panic text")
 }
 
+func TestStatusIs(t *testing.T) {
+	var err error = New(404, "Not Found", fmt.Errorf("read: %w", io.EOF))
+	if !errors.Is(err, io.EOF) {
+		t.Error("expected the status unwraps to its cause")
+	}
+	if !errors.Is(err, New(404, "another msg")) || errors.Is(err, New(500, "Not Found")) {
+		t.Error("expected the status matches on code")
+	}
+	wrapped := fmt.Errorf("handle: %w", err)
+	var stat *Status
+	if !errors.As(wrapped, &stat) || stat.Code() != 404 {
+		t.Errorf("expected errors.As finds the status, got %v", stat)
+	}
+	if New(1, "", err).Unwrap() != err.(*Status).cause {
+		t.Error("expected the status cause is not nested")
+	}
+	if (*Status)(nil).Unwrap() != nil {
+		t.Error("expected nil cause")
+	}
+}
+
 func testWithStack(err error) *Status {
 	return NewWithStack(404, "Not Found", err)
 }
+
+func TestStatusFields(t *testing.T) {
+	type badRequest struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	}
+	const typeURL = "type.googleapis.com/google.rpc.BadRequest"
+	expect := New(400, "invalid argument", "bala...bala...").
+		WithField("name", "andeya").
+		WithField("age", -1.0).
+		WithField("tags", []interface{}{"a", "b"})
+	if err := expect.AddDetail(typeURL, badRequest{Field: "age", Description: "must be positive"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expect.AddDetail("x", func() {}); err == nil {
+		t.Error("expected an error adding the unsupported detail")
+	}
+
+	b, err := expect.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := FromJSON(b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromQuery := FromQuery(expect.EncodeQuery(), false)
+	for _, stat := range []*Status{fromJSON, fromQuery} {
+		if !reflect.DeepEqual(stat.Fields(), expect.Fields()) {
+			t.Errorf("fields got:%v, want:%v", stat.Fields(), expect.Fields())
+		}
+		var detail badRequest
+		if ok, err := stat.Detail(typeURL, &detail); !ok || err != nil || detail.Field != "age" {
+			t.Errorf("unexpected detail: %v, %v, %+v", ok, err, detail)
+		}
+		if stat.Code() != 400 || stat.Msg() != "invalid argument" || stat.Cause().Error() != "bala...bala..." {
+			t.Errorf("got:%s, want:%s", stat, expect)
+		}
+	}
+	if ok, _ := fromJSON.Detail("unknown", new(badRequest)); ok {
+		t.Error("unexpected detail")
+	}
+
+	// The fields are kept by the copy, but not shared.
+	copy := expect.Copy(nil)
+	copy.WithField("name", "other")
+	if expect.Fields()["name"] != "andeya" || copy.Fields()["name"] != "other" || len(copy.Details()) != 1 {
+		t.Errorf("unexpected copy: %v", copy)
+	}
+	if New(0, "").Fields() != nil || New(0, "").Details() != nil {
+		t.Error("expected no fields and details")
+	}
+
+	// The values which can not be marshaled are set as their string forms,
+	// and encoded the same by MarshalJSON, EncodeQuery and MarshalBinary.
+	ch := make(chan int)
+	invalid := New(400, "").WithField("nan", math.NaN()).WithField("ch", ch)
+	if invalid.Fields()["nan"] != "NaN" || invalid.Fields()["ch"] != fmt.Sprintf("%v", ch) {
+		t.Fatalf("unexpected fields: %v", invalid.Fields())
+	}
+	invalid.fields["late"] = func() {}
+	b, err = invalid.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, _ = FromJSON(b, false)
+	fromQuery = FromQuery(invalid.EncodeQuery(), false)
+	bin, err := invalid.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromBinary, _ := FromBinary(bin, false)
+	for _, stat := range []*Status{fromJSON, fromQuery, fromBinary} {
+		if fields := stat.Fields(); len(fields) != 3 || fields["nan"] != "NaN" || fields["late"] == nil {
+			t.Errorf("unexpected fields: %v", fields)
+		}
+	}
+}
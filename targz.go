@@ -3,20 +3,34 @@ package goutil
 import (
 	"archive/tar"
 	"compress/gzip"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // TarGz compresses and archives tar.gz file.
 func TarGz(src, dst string, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) (err error) {
+	return TarGzWithMatcher(src, dst, includePrefix, logOutput, nil, ignoreElem...)
+}
+
+// TarGzTo compresses and archives tar.gz to dst writer.
+func TarGzTo(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) (err error) {
+	return TarGzToWithMatcher(src, dstWriter, includePrefix, logOutput, nil, ignoreElem...)
+}
+
+// TarGzWithMatcher is like TarGz,
+// but also skips the files matched by ignore, whose paths are relative to src.
+func TarGzWithMatcher(src, dst string, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) (err error) {
 	// Create dst file
 	fw, err := os.Create(dst)
 	if err != nil {
 		return
 	}
-	err = TarGzTo(src, fw, includePrefix, logOutput, ignoreElem...)
+	err = TarGzToWithMatcher(src, fw, includePrefix, logOutput, ignore, ignoreElem...)
 	fw.Close()
 	if err != nil {
 		os.Remove(dst)
@@ -24,21 +38,72 @@ func TarGz(src, dst string, includePrefix bool, logOutput func(string, ...interf
 	return err
 }
 
-// TarGzTo compresses and archives tar.gz to dst writer.
-func TarGzTo(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignoreElem ...string) (err error) {
-	src, err = filepath.Abs(src)
+// TarGzToWithMatcher is like TarGzTo,
+// but also skips the files matched by ignore, whose paths are relative to src.
+func TarGzToWithMatcher(src string, dstWriter io.Writer, includePrefix bool, logOutput func(string, ...interface{}), ignore *IgnoreMatcher, ignoreElem ...string) (err error) {
+	w, err := newArchiveWalker(src, includePrefix, ignoreElem)
 	if err != nil {
 		return
 	}
-	srcFi, err := os.Stat(src)
-	if err != nil {
-		return
-	}
-
+	w.ignore = ignore
 	gw := gzip.NewWriter(dstWriter)
 	defer gw.Close()
-	tw := tar.NewWriter(gw)
+	return writeTar(w, gw, "tar.gz", logOutput)
+}
+
+func writeTar(w *archiveWalker, dstWriter io.Writer, kind string, logOutput func(string, ...interface{})) error {
+	tw := tar.NewWriter(dstWriter)
 	defer tw.Close()
+	return w.walk(func(name, fileName string, fi os.FileInfo) error {
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		// Because hdr.Name is base name,
+		// once packaged, all files will pile up and destroy the original directory structure.
+		hdr.Name = filepath.ToSlash(name)
+
+		// write file infomation
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		n, err := copyFileTo(tw, fileName)
+		if err != nil {
+			return err
+		}
+		if logOutput != nil {
+			logOutput("%s: packaged %s, written %d bytes\n", kind, hdr.Name, n)
+		}
+		return nil
+	})
+}
+
+func copyFileTo(dst io.Writer, fileName string) (int64, error) {
+	fr, err := os.Open(fileName)
+	if err != nil {
+		return 0, err
+	}
+	defer fr.Close()
+	return io.Copy(dst, fr)
+}
+
+// archiveWalker walks the regular files to be archived.
+type archiveWalker struct {
+	src        string
+	prefix     string
+	ignoreElem []string
+	ignore     *IgnoreMatcher
+}
+
+func newArchiveWalker(src string, includePrefix bool, ignoreElem []string) (*archiveWalker, error) {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, err
+	}
+	srcFi, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
 
 	var separator = string(filepath.Separator)
 
@@ -50,7 +115,6 @@ func TarGzTo(src string, dstWriter io.Writer, includePrefix bool, logOutput func
 		}
 		a = append(a, v)
 	}
-	ignoreElem = append(a, ".DS_Store")
 
 	var prefix string
 	if !srcFi.IsDir() || includePrefix {
@@ -58,27 +122,42 @@ func TarGzTo(src string, dstWriter io.Writer, includePrefix bool, logOutput func
 	} else {
 		prefix = src + separator
 	}
+	return &archiveWalker{
+		src:        src,
+		prefix:     prefix,
+		ignoreElem: append(a, ".DS_Store"),
+	}, nil
+}
 
-	return filepath.Walk(src, func(fileName string, fi os.FileInfo, err error) error {
+// walk calls fn for each regular file that is not ignored,
+// name is the path in archive, and fileName is the path in file system.
+func (w *archiveWalker) walk(fn func(name, fileName string, fi os.FileInfo) error) error {
+	var separator = string(filepath.Separator)
+	return filepath.Walk(w.src, func(fileName string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		name := strings.TrimPrefix(fileName, w.prefix)
 
-		hdr, err := tar.FileInfoHeader(fi, "")
-		if err != nil {
-			return err
+		if w.ignore != nil && fileName != w.src {
+			rel, err := filepath.Rel(w.src, fileName)
+			if err != nil {
+				return err
+			}
+			if w.ignore.Match(rel, fi.IsDir()) {
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 		}
 
-		// Because hdr.Name is base name,
-		// once packaged, all files will pile up and destroy the original directory structure.
-		hdr.Name = strings.TrimPrefix(fileName, prefix)
-
 		// ignore files
-		for _, v := range ignoreElem {
-			if hdr.Name == v ||
-				strings.HasPrefix(hdr.Name, v+separator) ||
-				strings.HasSuffix(hdr.Name, separator+v) ||
-				strings.Contains(hdr.Name, separator+v+separator) {
+		for _, v := range w.ignoreElem {
+			if name == v ||
+				strings.HasPrefix(name, v+separator) ||
+				strings.HasSuffix(name, separator+v) ||
+				strings.Contains(name, separator+v+separator) {
 				return nil
 			}
 		}
@@ -87,25 +166,260 @@ func TarGzTo(src string, dstWriter io.Writer, includePrefix bool, logOutput func
 		if !fi.Mode().IsRegular() {
 			return nil
 		}
+		return fn(name, fileName, fi)
+	})
+}
 
-		// write file infomation
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
+// ExtractLimit limits the extraction of an archive, against decompression bombs.
+// A non-positive field means no limit.
+type ExtractLimit struct {
+	// MaxBytes is the maximum total size of the extracted file contents,
+	// and also of the zip archive read from a non-seekable stream, which is spooled to a temporary file.
+	MaxBytes int64
+	// MaxEntries is the maximum number of entries in the archive.
+	MaxEntries int
+}
+
+var (
+	// ErrExtractTooLarge is returned when the extracted contents exceed ExtractLimit.MaxBytes.
+	ErrExtractTooLarge = errors.New("extract: total size exceeds the limit")
+	// ErrExtractTooManyEntries is returned when the archive has more entries than ExtractLimit.MaxEntries.
+	ErrExtractTooManyEntries = errors.New("extract: number of entries exceeds the limit")
+)
 
-		fr, err := os.Open(fileName)
-		defer fr.Close()
+// UnTarGz decompresses and extracts the tar.gz file src into the dstDir directory.
+// It restores regular files, directories, symbolic links, hard links, modes and mtimes.
+// NOTE:
+//
+//	Entries with absolute paths or escaping dstDir (zip slip) are rejected;
+//	If limit is empty, there is no limit.
+func UnTarGz(src, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	fr, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+	return UnTarGzFrom(fr, dstDir, logOutput, limit...)
+}
+
+// UnTarGzFrom decompresses and extracts tar.gz from the src reader into the dstDir directory.
+// NOTE:
+//
+//	Entries with absolute paths or escaping dstDir (zip slip) are rejected;
+//	If limit is empty, there is no limit.
+func UnTarGzFrom(src io.Reader, dstDir string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	gr, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	return untar(gr, dstDir, "tar.gz", logOutput, limit...)
+}
+
+func untar(src io.Reader, dstDir, kind string, logOutput func(string, ...interface{}), limit ...ExtractLimit) error {
+	x, err := newExtractor(dstDir, limit...)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			return err
 		}
-
-		n, err := io.Copy(tw, fr)
+		if err = x.countEntry(); err != nil {
+			return err
+		}
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(hdr.Name, mode, hdr.ModTime)
+		case tar.TypeReg, tar.TypeRegA:
+			var n int64
+			n, err = x.writeFile(hdr.Name, tr, mode, hdr.ModTime)
+			if err == nil && logOutput != nil {
+				logOutput("%s: extracted %s, written %d bytes\n", kind, hdr.Name, n)
+			}
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.link(hdr.Name, hdr.Linkname)
+		default:
+			// Device files, FIFOs and PAX/GNU meta entries are not extracted.
+		}
 		if err != nil {
 			return err
 		}
-		if logOutput != nil {
-			logOutput("tar.gz: packaged %s, written %d bytes\n", hdr.Name, n)
+	}
+	return x.finish()
+}
+
+// extractor writes archive entries into a destination directory safely.
+type extractor struct {
+	root    string
+	limit   ExtractLimit
+	entries int
+	written int64
+	dirs    []dirTime
+}
+
+type dirTime struct {
+	path    string
+	modTime time.Time
+}
+
+func newExtractor(dstDir string, limit ...ExtractLimit) (*extractor, error) {
+	root, err := filepath.Abs(dstDir)
+	if err != nil {
+		return nil, err
+	}
+	if err = MkdirAll(root); err != nil {
+		return nil, err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, err
+	}
+	x := &extractor{root: root}
+	if len(limit) > 0 {
+		x.limit = limit[0]
+	}
+	return x, nil
+}
+
+func (x *extractor) countEntry() error {
+	x.entries++
+	if x.limit.MaxEntries > 0 && x.entries > x.limit.MaxEntries {
+		return ErrExtractTooManyEntries
+	}
+	return nil
+}
+
+// resolve returns the absolute target path of the archive entry name,
+// and rejects absolute names and names escaping the root directory.
+func (x *extractor) resolve(name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || strings.HasPrefix(name, string(filepath.Separator)) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("extract: illegal absolute path %q", name)
+	}
+	target := filepath.Join(x.root, name)
+	if !x.within(target) {
+		return "", fmt.Errorf("extract: illegal path %q escapes the destination", name)
+	}
+	return target, nil
+}
+
+func (x *extractor) within(target string) bool {
+	rel, err := filepath.Rel(x.root, target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (x *extractor) mkdir(name string, mode os.FileMode, modTime time.Time) error {
+	target, err := x.resolve(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	if err = os.Chmod(target, mode.Perm()|0700); err != nil {
+		return err
+	}
+	// The mtimes of directories are restored at the end,
+	// because extracting their children modifies them.
+	x.dirs = append(x.dirs, dirTime{path: target, modTime: modTime})
+	return nil
+}
+
+func (x *extractor) writeFile(name string, r io.Reader, mode os.FileMode, modTime time.Time) (int64, error) {
+	target, err := x.resolve(name)
+	if err != nil {
+		return 0, err
+	}
+	if err = MkdirAll(filepath.Dir(target)); err != nil {
+		return 0, err
+	}
+	// Remove the existing file or symlink, so that an old symlink cannot redirect the writing.
+	if err = os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	fw, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return 0, err
+	}
+	if x.limit.MaxBytes > 0 {
+		r = io.LimitReader(r, x.limit.MaxBytes-x.written+1)
+	}
+	n, err := io.Copy(fw, r)
+	x.written += n
+	if closeErr := fw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	if x.limit.MaxBytes > 0 && x.written > x.limit.MaxBytes {
+		return n, ErrExtractTooLarge
+	}
+	if err = os.Chmod(target, mode.Perm()); err != nil {
+		return n, err
+	}
+	return n, os.Chtimes(target, modTime, modTime)
+}
+
+func (x *extractor) symlink(name, linkname string) error {
+	target, err := x.resolve(name)
+	if err != nil {
+		return err
+	}
+	if err = MkdirAll(filepath.Dir(target)); err != nil {
+		return err
+	}
+	// Check against the real parent directory, and keep only leading ".." elements in the link,
+	// so that no symlink, even chained with others, can point outside the destination.
+	parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	linkname = filepath.Clean(filepath.FromSlash(linkname))
+	if filepath.IsAbs(linkname) || filepath.VolumeName(linkname) != "" || !x.within(filepath.Join(parent, linkname)) {
+		return fmt.Errorf("extract: illegal symlink %q -> %q escapes the destination", name, linkname)
+	}
+	if err = os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
+
+func (x *extractor) link(name, linkname string) error {
+	target, err := x.resolve(name)
+	if err != nil {
+		return err
+	}
+	oldname, err := x.resolve(linkname)
+	if err != nil {
+		return err
+	}
+	if err = MkdirAll(filepath.Dir(target)); err != nil {
+		return err
+	}
+	if err = os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Link(oldname, target)
+}
+
+func (x *extractor) finish() error {
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		d := x.dirs[i]
+		if err := os.Chtimes(d.path, d.modTime, d.modTime); err != nil {
+			return err
 		}
-		return nil
-	})
+	}
+	return nil
 }
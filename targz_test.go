@@ -1,7 +1,11 @@
 package goutil
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -11,3 +15,60 @@ func TestTarGzTo(t *testing.T) {
 	err := TarGzTo(src, dstWriter, false, t.Logf, ".git")
 	_ = err
 }
+
+func TestUnTarGzFrom(t *testing.T) {
+	src := t.TempDir()
+	if err := WriteFile(filepath.Join(src, "a", "b.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(filepath.Join(src, "c.sh"), []byte("echo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var buf = bytes.NewBuffer(nil)
+	if err := TarGzTo(src, buf, false, t.Logf); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+	if err := UnTarGzFrom(bytes.NewReader(buf.Bytes()), dst, t.Logf); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dst, "a", "b.txt"))
+	if err != nil || string(b) != "hello" {
+		t.Fatalf("got %q, %v", b, err)
+	}
+	fi, err := os.Stat(filepath.Join(dst, "c.sh"))
+	if err != nil || fi.Mode().Perm() != 0755 {
+		t.Fatalf("got %v, %v", fi, err)
+	}
+
+	err = UnTarGzFrom(bytes.NewReader(buf.Bytes()), t.TempDir(), nil, ExtractLimit{MaxEntries: 1})
+	if err != ErrExtractTooManyEntries {
+		t.Fatalf("expect ErrExtractTooManyEntries, got %v", err)
+	}
+	err = UnTarGzFrom(bytes.NewReader(buf.Bytes()), t.TempDir(), nil, ExtractLimit{MaxBytes: 6})
+	if err != ErrExtractTooLarge {
+		t.Fatalf("expect ErrExtractTooLarge, got %v", err)
+	}
+}
+
+func TestUnTarGzFromIllegal(t *testing.T) {
+	var cases = []tar.Header{
+		{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "/etc/evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+		{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+	}
+	for _, hdr := range cases {
+		var buf = bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gw)
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Close()
+		gw.Close()
+		if err := UnTarGzFrom(buf, t.TempDir(), nil); err == nil {
+			t.Fatalf("%s: expect error, got nil", hdr.Name)
+		}
+	}
+}